@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a load test run and the expectations it must meet
+type Config struct {
+	Target  string        `yaml:"target"`
+	Token   string        `yaml:"-"`
+	Warmup  time.Duration `yaml:"warmup"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Duration of the measured phase
+	Duration time.Duration `yaml:"duration"`
+	// SeedExperiments are created before the run for get/update scenarios
+	SeedExperiments int `yaml:"seed_experiments"`
+	// ExperimentSpec is sent as the spec of every created experiment
+	ExperimentSpec map[string]interface{} `yaml:"experiment_spec"`
+	Scenarios      []Scenario             `yaml:"scenarios"`
+}
+
+// Scenario drives one operation at a fixed rate
+type Scenario struct {
+	Name        string  `yaml:"name"`
+	Operation   string  `yaml:"operation"`
+	RPS         float64 `yaml:"rps"`
+	MaxInFlight int     `yaml:"max_in_flight"`
+	// Hold keeps update streams open this long
+	Hold   time.Duration `yaml:"hold"`
+	Budget Budget        `yaml:"budget"`
+}
+
+// Budget is the baseline a scenario must stay within. Zero values are not
+// checked.
+type Budget struct {
+	MaxP50       time.Duration `yaml:"max_p50"`
+	MaxP99       time.Duration `yaml:"max_p99"`
+	MaxErrorRate float64       `yaml:"max_error_rate"`
+}
+
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		Target:          "http://localhost:8080",
+		Warmup:          5 * time.Second,
+		Timeout:         10 * time.Second,
+		Duration:        time.Minute,
+		SeedExperiments: 5,
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if len(cfg.Scenarios) == 0 {
+		return nil, fmt.Errorf("%s: no scenarios", path)
+	}
+	for i := range cfg.Scenarios {
+		sc := &cfg.Scenarios[i]
+		if _, ok := operations[sc.Operation]; !ok {
+			return nil, fmt.Errorf("scenario %q: unknown operation %q", sc.Name, sc.Operation)
+		}
+		if sc.RPS <= 0 {
+			return nil, fmt.Errorf("scenario %q: rps must be positive", sc.Name)
+		}
+		if sc.Name == "" {
+			sc.Name = sc.Operation
+		}
+		if sc.MaxInFlight <= 0 {
+			sc.MaxInFlight = int(sc.RPS*2) + 1
+		}
+	}
+	return cfg, nil
+}
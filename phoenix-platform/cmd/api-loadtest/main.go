@@ -0,0 +1,220 @@
+// api-loadtest drives the platform API's REST and streaming endpoints at a
+// fixed rate per scenario and checks the latencies and error rates against
+// the baseline in its config, so performance regressions are caught before
+// a release.
+//
+//	api-loadtest -config configs/loadtest/api-baseline.yaml -target https://phoenix-api.staging:8080
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/loadtest/api-baseline.yaml", "load test configuration")
+	target := flag.String("target", "", "API base URL, overrides the config")
+	duration := flag.Duration("duration", 0, "measured duration, overrides the config")
+	jsonOut := flag.String("json", "", "also write the report as JSON to this file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "api-loadtest: %v\n", err)
+		os.Exit(2)
+	}
+	if *target != "" {
+		cfg.Target = *target
+	}
+	if *duration > 0 {
+		cfg.Duration = *duration
+	}
+	cfg.Token = os.Getenv("PHOENIX_TOKEN")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	results, err := run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "api-loadtest: %v\n", err)
+		os.Exit(2)
+	}
+
+	printReport(results)
+	if *jsonOut != "" {
+		data, _ := json.MarshalIndent(results, "", "  ")
+		if err := os.WriteFile(*jsonOut, data, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "api-loadtest: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	for _, r := range results {
+		if len(r.Violations) > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+func run(ctx context.Context, cfg *Config) ([]Result, error) {
+	client := newAPIClient(cfg)
+
+	// Experiments used by the get, status, update and stream scenarios
+	for i := 0; i < cfg.SeedExperiments; i++ {
+		id, _, err := client.create(ctx)
+		if err != nil {
+			client.cleanup()
+			return nil, fmt.Errorf("failed to seed experiments: %w", err)
+		}
+		client.seeded = append(client.seeded, id)
+	}
+	defer client.cleanup()
+
+	if cfg.Warmup > 0 {
+		fmt.Fprintf(os.Stderr, "warming up for %s\n", cfg.Warmup)
+		drive(ctx, client, cfg.Scenarios, cfg.Warmup)
+	}
+
+	fmt.Fprintf(os.Stderr, "running %d scenarios against %s for %s\n", len(cfg.Scenarios), cfg.Target, cfg.Duration)
+	start := time.Now()
+	stats := drive(ctx, client, cfg.Scenarios, cfg.Duration)
+	elapsed := time.Since(start)
+
+	results := make([]Result, len(cfg.Scenarios))
+	for i, sc := range cfg.Scenarios {
+		results[i] = stats[i].result(sc, elapsed)
+		results[i].Violations = checkBudget(sc.Budget, results[i])
+	}
+	return results, nil
+}
+
+// drive runs every scenario concurrently for d
+func drive(ctx context.Context, client *apiClient, scenarios []Scenario, d time.Duration) []*Stats {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	stats := make([]*Stats, len(scenarios))
+	var wg sync.WaitGroup
+	for i, sc := range scenarios {
+		stats[i] = &Stats{}
+		wg.Add(1)
+		go func(sc Scenario, st *Stats) {
+			defer wg.Done()
+			driveScenario(ctx, client, sc, st)
+		}(sc, stats[i])
+	}
+	wg.Wait()
+	return stats
+}
+
+// driveScenario fires one operation per tick. Ticks that find MaxInFlight
+// requests still outstanding are dropped instead of queued, so a slow API
+// cannot hide behind a growing backlog.
+func driveScenario(ctx context.Context, client *apiClient, sc Scenario, stats *Stats) {
+	op := operations[sc.Operation]
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / sc.RPS))
+	defer ticker.Stop()
+
+	inFlight := make(chan struct{}, sc.MaxInFlight)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			stats.drop()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			latency, err := op(ctx, client, sc)
+			switch {
+			case errors.Is(err, errSkipped):
+				stats.skip()
+			case err != nil && ctx.Err() != nil:
+				// Cut off by the end of the run, not a failure
+			default:
+				stats.record(latency, err)
+			}
+		}()
+	}
+}
+
+func checkBudget(b Budget, r Result) []string {
+	var violations []string
+	if b.MaxP50 > 0 && r.P50 > b.MaxP50 {
+		violations = append(violations, fmt.Sprintf("p50 %s exceeds %s", r.P50, b.MaxP50))
+	}
+	if b.MaxP99 > 0 && r.P99 > b.MaxP99 {
+		violations = append(violations, fmt.Sprintf("p99 %s exceeds %s", r.P99, b.MaxP99))
+	}
+	if b.MaxErrorRate > 0 && r.ErrorRate > b.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %.2f%% exceeds %.2f%%", r.ErrorRate*100, b.MaxErrorRate*100))
+	}
+	return violations
+}
+
+// cleanup deletes every experiment the run created
+func (c *apiClient) cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	c.mu.Lock()
+	ids := append(append([]string(nil), c.seeded...), c.created...)
+	c.created = nil
+	c.mu.Unlock()
+
+	for _, id := range ids {
+		if _, err := c.delete(ctx, id); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to delete experiment %s: %v\n", id, err)
+		}
+	}
+}
+
+func printReport(results []Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCENARIO\tRPS\tREQUESTS\tERRORS\tDROPPED\tP50\tP90\tP99\tMAX\tRESULT")
+	for _, r := range results {
+		verdict := "ok"
+		if len(r.Violations) > 0 {
+			verdict = "FAIL"
+		}
+		fmt.Fprintf(w, "%s\t%.1f/%.1f\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			r.Scenario, r.ActualRPS, r.TargetRPS, r.Requests, r.Errors, r.Dropped,
+			round(r.P50), round(r.P90), round(r.P99), round(r.Max), verdict)
+	}
+	w.Flush()
+
+	for _, r := range results {
+		for _, v := range r.Violations {
+			fmt.Printf("%s: %s\n", r.Scenario, v)
+		}
+		if r.LastError != "" {
+			fmt.Printf("%s: last error: %s\n", r.Scenario, r.LastError)
+		}
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(100 * time.Microsecond)
+}
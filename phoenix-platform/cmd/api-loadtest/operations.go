@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errSkipped marks a tick with nothing to do, e.g. no experiment left to delete
+var errSkipped = errors.New("skipped")
+
+// operation performs one request and returns its latency
+type operation func(ctx context.Context, c *apiClient, sc Scenario) (time.Duration, error)
+
+var operations = map[string]operation{
+	"list":   listExperiments,
+	"get":    getExperiment,
+	"status": getExperimentStatus,
+	"create": createExperiment,
+	"update": updateExperiment,
+	"delete": deleteExperiment,
+	"stream": subscribe,
+}
+
+// apiClient talks to the REST gateway and keeps the experiments it created
+type apiClient struct {
+	cfg  *Config
+	http *http.Client
+	// stream has no overall timeout, for responses held open
+	stream *http.Client
+	seeded []string
+
+	mu      sync.Mutex
+	created []string
+	rng     *rand.Rand
+}
+
+func newAPIClient(cfg *Config) *apiClient {
+	return &apiClient{
+		cfg:    cfg,
+		http:   &http.Client{Timeout: cfg.Timeout},
+		stream: &http.Client{},
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) (time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.cfg.Target, "/")+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	elapsed := time.Since(start)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return 0, fmt.Errorf("%s %s: %w", method, path, err)
+		}
+	}
+	return elapsed, nil
+}
+
+func (c *apiClient) randomSeeded() (string, error) {
+	if len(c.seeded) == 0 {
+		return "", errSkipped
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seeded[c.rng.Intn(len(c.seeded))], nil
+}
+
+func (c *apiClient) spec(suffix string) map[string]interface{} {
+	spec := make(map[string]interface{}, len(c.cfg.ExperimentSpec)+1)
+	for k, v := range c.cfg.ExperimentSpec {
+		spec[k] = v
+	}
+	spec["name"] = fmt.Sprintf("loadtest-%s", suffix)
+	return spec
+}
+
+// create creates an experiment and returns its ID
+func (c *apiClient) create(ctx context.Context) (string, time.Duration, error) {
+	var resp map[string]interface{}
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	elapsed, err := c.do(ctx, http.MethodPost, "/api/v1/experiments", map[string]interface{}{"spec": c.spec(suffix)}, &resp)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// The gateway may emit either proto or JSON field names
+	for _, key := range []string{"experiment_id", "experimentId"} {
+		if id, ok := resp[key].(string); ok && id != "" {
+			return id, elapsed, nil
+		}
+	}
+	return "", 0, fmt.Errorf("create response has no experiment id")
+}
+
+func (c *apiClient) delete(ctx context.Context, id string) (time.Duration, error) {
+	return c.do(ctx, http.MethodDelete, "/api/v1/experiments/"+url.PathEscape(id), nil, nil)
+}
+
+func listExperiments(ctx context.Context, c *apiClient, sc Scenario) (time.Duration, error) {
+	return c.do(ctx, http.MethodGet, "/api/v1/experiments?limit=20", nil, nil)
+}
+
+func getExperiment(ctx context.Context, c *apiClient, sc Scenario) (time.Duration, error) {
+	id, err := c.randomSeeded()
+	if err != nil {
+		return 0, err
+	}
+	return c.do(ctx, http.MethodGet, "/api/v1/experiments/"+url.PathEscape(id), nil, nil)
+}
+
+func getExperimentStatus(ctx context.Context, c *apiClient, sc Scenario) (time.Duration, error) {
+	id, err := c.randomSeeded()
+	if err != nil {
+		return 0, err
+	}
+	return c.do(ctx, http.MethodGet, "/api/v1/experiments/"+url.PathEscape(id)+"/status", nil, nil)
+}
+
+func createExperiment(ctx context.Context, c *apiClient, sc Scenario) (time.Duration, error) {
+	id, elapsed, err := c.create(ctx)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.created = append(c.created, id)
+	c.mu.Unlock()
+	return elapsed, nil
+}
+
+func updateExperiment(ctx context.Context, c *apiClient, sc Scenario) (time.Duration, error) {
+	id, err := c.randomSeeded()
+	if err != nil {
+		return 0, err
+	}
+	body := map[string]interface{}{"spec": c.spec(id)}
+	return c.do(ctx, http.MethodPatch, "/api/v1/experiments/"+url.PathEscape(id), body, nil)
+}
+
+func deleteExperiment(ctx context.Context, c *apiClient, sc Scenario) (time.Duration, error) {
+	c.mu.Lock()
+	if len(c.created) == 0 {
+		c.mu.Unlock()
+		return 0, errSkipped
+	}
+	id := c.created[len(c.created)-1]
+	c.created = c.created[:len(c.created)-1]
+	c.mu.Unlock()
+
+	return c.delete(ctx, id)
+}
+
+// subscribe streams updates of a seeded experiment through the gateway's
+// mapping of StreamExperimentUpdates and holds the stream open. The server
+// sends the experiment's current state first; the latency runs until that
+// update arrives.
+func subscribe(ctx context.Context, c *apiClient, sc Scenario) (time.Duration, error) {
+	id, err := c.randomSeeded()
+	if err != nil {
+		return 0, err
+	}
+	path := "/api/v1/experiments/" + url.PathEscape(id) + "/updates"
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.cfg.Target, "/")+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	// The stream outlives the request timeout, so only the wait for the
+	// first update is bounded by it
+	start := time.Now()
+	timeout := time.AfterFunc(c.cfg.Timeout, cancel)
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("GET %s: status %d", path, resp.StatusCode)
+	}
+
+	// The gateway writes one JSON object per message
+	var first struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&first); err != nil {
+		return 0, fmt.Errorf("GET %s: no update: %w", path, err)
+	}
+	elapsed := time.Since(start)
+	if !timeout.Stop() {
+		return 0, fmt.Errorf("GET %s: no update within %s", path, c.cfg.Timeout)
+	}
+	if first.Error != nil {
+		return 0, fmt.Errorf("GET %s: %s", path, first.Error.Message)
+	}
+	if len(first.Result) == 0 {
+		return 0, fmt.Errorf("GET %s: first message is not an update", path)
+	}
+
+	hold := time.NewTimer(sc.Hold)
+	defer hold.Stop()
+	select {
+	case <-hold.C:
+	case <-ctx.Done():
+	}
+	return elapsed, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// histogramBounds are the upper bounds of the latency buckets
+var histogramBounds = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second,
+}
+
+// Stats collects the outcomes of one scenario
+type Stats struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+	dropped   int
+	skipped   int
+	lastError string
+}
+
+func (s *Stats) record(d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.errors++
+		s.lastError = err.Error()
+		return
+	}
+	s.latencies = append(s.latencies, d)
+}
+
+func (s *Stats) drop() {
+	s.mu.Lock()
+	s.dropped++
+	s.mu.Unlock()
+}
+
+func (s *Stats) skip() {
+	s.mu.Lock()
+	s.skipped++
+	s.mu.Unlock()
+}
+
+// Bucket is one histogram bucket; the last bucket has no upper bound
+type Bucket struct {
+	UpperBound string `json:"le"`
+	Count      int    `json:"count"`
+}
+
+// Result summarizes a scenario for the report
+type Result struct {
+	Scenario   string        `json:"scenario"`
+	Operation  string        `json:"operation"`
+	TargetRPS  float64       `json:"target_rps"`
+	ActualRPS  float64       `json:"actual_rps"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	Dropped    int           `json:"dropped"`
+	Skipped    int           `json:"skipped"`
+	ErrorRate  float64       `json:"error_rate"`
+	P50        time.Duration `json:"p50"`
+	P90        time.Duration `json:"p90"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	Histogram  []Bucket      `json:"histogram"`
+	LastError  string        `json:"last_error,omitempty"`
+	Violations []string      `json:"violations,omitempty"`
+}
+
+func (s *Stats) result(sc Scenario, elapsed time.Duration) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	latencies := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	r := Result{
+		Scenario:  sc.Name,
+		Operation: sc.Operation,
+		TargetRPS: sc.RPS,
+		Requests:  len(latencies) + s.errors,
+		Errors:    s.errors,
+		Dropped:   s.dropped,
+		Skipped:   s.skipped,
+		P50:       percentile(latencies, 0.50),
+		P90:       percentile(latencies, 0.90),
+		P99:       percentile(latencies, 0.99),
+		LastError: s.lastError,
+	}
+	if len(latencies) > 0 {
+		r.Max = latencies[len(latencies)-1]
+	}
+	if elapsed > 0 {
+		r.ActualRPS = float64(r.Requests) / elapsed.Seconds()
+	}
+	// Requests the generator could not send count against the budget too,
+	// otherwise an overloaded API would look healthy
+	if attempted := r.Requests + r.Dropped; attempted > 0 {
+		r.ErrorRate = float64(r.Errors+r.Dropped) / float64(attempted)
+	}
+
+	r.Histogram = make([]Bucket, len(histogramBounds)+1)
+	for i, bound := range histogramBounds {
+		r.Histogram[i].UpperBound = bound.String()
+	}
+	r.Histogram[len(histogramBounds)].UpperBound = "+Inf"
+	for _, d := range latencies {
+		i := sort.Search(len(histogramBounds), func(i int) bool { return d <= histogramBounds[i] })
+		r.Histogram[i].Count++
+	}
+	return r
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted)-1) * p)
+	return sorted[i]
+}
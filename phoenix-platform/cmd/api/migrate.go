@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// runMigrations applies pending schema migrations before the store is opened.
+// With DB_AUTO_MIGRATE=false, operators apply them with "phoenix admin
+// migrate" instead.
+func runMigrations(dbURL string, logger *zap.Logger) error {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	applied, err := store.Migrate(ctx, db)
+	for _, m := range applied {
+		logger.Info("applied migration", zap.Int("version", m.Version), zap.String("name", m.Name))
+	}
+	return err
+}
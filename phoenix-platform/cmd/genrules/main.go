@@ -0,0 +1,126 @@
+// genrules writes the Prometheus recording rules for the KPIs Phoenix
+// dashboards, alerts and analysis rely on, and, to a separate file, the
+// multi-window burn-rate alerts for the candidate cardinality SLO.
+//
+//	genrules -variants candidate,topk-10 -environment staging -output rules.yml -alerts-output slo_alerts.yml
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const header = "# Code generated by genrules. DO NOT EDIT.\n"
+
+func main() {
+	variants := flag.String("variants", "candidate", "comma separated variants to compare against the baseline")
+	baseline := flag.String("baseline", "baseline", "baseline variant name")
+	environment := flag.String("environment", "", "environment label added to every rule")
+	interval := flag.String("interval", "30s", "rule group evaluation interval")
+	costPerGB := flag.Float64("cost-per-gb", 0.25, "ingest price per GB used for cost estimates")
+	sloThreshold := flag.Float64("slo-threshold", 25000, "process cardinality a candidate must stay below")
+	sloObjective := flag.Float64("slo-objective", 0.99, "share of time a candidate must stay below -slo-threshold")
+	output := flag.String("output", "", "recording rules file to write, stdout when empty")
+	alertsOutput := flag.String("alerts-output", "", "SLO alerts file to write, stdout when empty")
+	check := flag.Bool("check", false, "validate the output with promtool check rules")
+	flag.Parse()
+
+	if err := run(Options{
+		Environment:  *environment,
+		Baseline:     *baseline,
+		Variants:     splitList(*variants),
+		Interval:     *interval,
+		CostPerGB:    *costPerGB,
+		SLOThreshold: *sloThreshold,
+		SLOObjective: *sloObjective,
+	}, *output, *alertsOutput, *check); err != nil {
+		fmt.Fprintf(os.Stderr, "genrules: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts Options, output, alertsOutput string, check bool) error {
+	rules, err := Generate(opts)
+	if err != nil {
+		return err
+	}
+	alerts, err := GenerateAlerts(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := write(rules, output, check); err != nil {
+		return err
+	}
+	if output == "" && alertsOutput == "" {
+		// Both files go to stdout, as separate YAML documents
+		if _, err := os.Stdout.WriteString("---\n"); err != nil {
+			return err
+		}
+	}
+	return write(alerts, alertsOutput, check)
+}
+
+// write renders a rules file, checks it with promtool if asked and writes it
+// to path, or stdout when path is empty
+func write(rules *RuleFile, path string, check bool) error {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(rules); err != nil {
+		return err
+	}
+
+	if check {
+		if err := promtoolCheck(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if path == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// promtoolCheck runs promtool against the rendered rules so syntax errors
+// surface at generation time rather than on Prometheus reload
+func promtoolCheck(rules []byte) error {
+	f, err := os.CreateTemp("", "phoenix-rules-*.yml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(rules); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	out, err := exec.Command("promtool", "check", "rules", f.Name()).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("promtool check rules failed: %w\n%s", err, out)
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sc "github.com/phoenix/platform/pkg/semconv"
+)
+
+// Rule is a Prometheus recording or alerting rule
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Group is a Prometheus rule group
+type Group struct {
+	Name     string `yaml:"name"`
+	Interval string `yaml:"interval,omitempty"`
+	Rules    []Rule `yaml:"rules"`
+}
+
+// RuleFile is the top level of a Prometheus rules file
+type RuleFile struct {
+	Groups []Group `yaml:"groups"`
+}
+
+// Options parameterize the generated rules
+type Options struct {
+	Environment string
+	Baseline    string
+	Variants    []string
+	Interval    string
+	// CostPerGB is the ingest price used for cost estimates
+	CostPerGB float64
+	// SLOThreshold is the process cardinality a candidate must stay below
+	// for SLOObjective of the time, e.g. 25000 and 0.99
+	SLOThreshold float64
+	SLOObjective float64
+}
+
+// recordNamePattern enforces the level:metric:operations naming convention
+var recordNamePattern = regexp.MustCompile(`^phoenix:[a-z0-9_]+(:[a-z0-9_]+)?$`)
+
+func (opts Options) validate() error {
+	if opts.Baseline == "" {
+		return fmt.Errorf("baseline variant is required")
+	}
+	if len(opts.Variants) == 0 {
+		return fmt.Errorf("at least one variant is required")
+	}
+	if opts.SLOThreshold <= 0 {
+		return fmt.Errorf("SLO threshold must be positive")
+	}
+	if opts.SLOObjective <= 0 || opts.SLOObjective >= 1 {
+		return fmt.Errorf("SLO objective must be between 0 and 1")
+	}
+	return nil
+}
+
+// Generate builds the recording rules for every Phoenix-derived KPI,
+// including the error ratios the SLO alerts are evaluated against
+func Generate(opts Options) (*RuleFile, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	collectorPods := sc.Re(sc.LabelPod, "phoenix-collector-.*")
+	exportSent := sc.SumBy("rate("+sc.CollectorExporterSentPoints+"[5m])", sc.LabelExperimentID, sc.LabelVariant)
+	exportFailed := sc.SumBy("rate("+sc.CollectorExporterSendFailedPoints+"[5m])", sc.LabelExperimentID, sc.LabelVariant)
+	shared := Group{
+		Name:     "phoenix_kpis",
+		Interval: opts.Interval,
+		Rules: []Rule{
+			{
+				Record: sc.RuleCardinalityGrowthRate,
+				Expr:   sc.SumBy("deriv("+sc.ProcessCardinality+"[15m])", sc.LabelExperimentID, sc.LabelVariant) + " * 3600",
+				Labels: map[string]string{sc.LabelMetricType: sc.MetricTypeEfficiency},
+			},
+			{
+				Record: sc.RuleEstimatedCostHourly,
+				Expr: fmt.Sprintf("%s * 3600 / 1073741824 * %g",
+					sc.SumBy("rate("+sc.PipelineBytesExported+"[5m])", sc.LabelExperimentID, sc.LabelVariant), opts.CostPerGB),
+				Labels: map[string]string{sc.LabelMetricType: sc.MetricTypeCost},
+			},
+			{
+				Record: sc.RuleExportErrorRatio,
+				Expr:   fmt.Sprintf("%s / (%s + %s)", exportFailed, exportSent, exportFailed),
+				Labels: map[string]string{sc.LabelMetricType: sc.MetricTypeQuality},
+			},
+			{
+				Record: sc.RuleCollectorCPU,
+				Expr:   "rate(" + sc.Selector(sc.ContainerCPUUsage, collectorPods) + "[5m])",
+				Labels: map[string]string{sc.LabelMetricType: sc.MetricTypePerformance},
+			},
+			{
+				Record: sc.RuleCollectorMemory,
+				Expr:   sc.Selector(sc.ContainerMemoryWorkingSet, collectorPods),
+				Labels: map[string]string{sc.LabelMetricType: sc.MetricTypePerformance},
+			},
+		},
+	}
+
+	file := &RuleFile{Groups: []Group{shared}}
+
+	for _, variant := range opts.Variants {
+		if variant == opts.Baseline {
+			continue
+		}
+		file.Groups = append(file.Groups, variantGroup(opts, variant))
+	}
+	file.Groups = append(file.Groups, sloGroup(opts))
+
+	for i := range file.Groups {
+		for j := range file.Groups[i].Rules {
+			rule := &file.Groups[i].Rules[j]
+			if !recordNamePattern.MatchString(rule.Record) {
+				return nil, fmt.Errorf("rule %s does not follow the phoenix:<metric>[:<operation>] convention", rule.Record)
+			}
+			if _, ok := sc.Lookup(rule.Record); !ok {
+				return nil, fmt.Errorf("rule %s is not registered in pkg/semconv", rule.Record)
+			}
+			if opts.Environment != "" {
+				rule.Labels[sc.LabelEnvironment] = opts.Environment
+			}
+		}
+	}
+
+	return file, nil
+}
+
+// variantGroup compares one candidate variant against the baseline
+func variantGroup(opts Options, variant string) Group {
+	cardinality := func(v string) string {
+		return sc.SumBy(sc.Selector(sc.ProcessCardinality, sc.Eq(sc.LabelVariant, v)), sc.LabelExperimentID)
+	}
+	executables := func(v string, extra ...sc.Matcher) string {
+		matchers := append([]sc.Matcher{sc.Eq(sc.LabelVariant, v)}, extra...)
+		perExecutable := sc.CountBy(sc.Selector(sc.ProcessCPUTime, matchers...), sc.LabelExperimentID, sc.LabelProcessExecutableName)
+		return sc.CountBy(perExecutable, sc.LabelExperimentID)
+	}
+	critical := sc.Eq(sc.LabelProcessPriority, sc.PriorityCritical)
+	labels := func(metricType string) map[string]string {
+		return map[string]string{sc.LabelMetricType: metricType, sc.LabelVariant: variant}
+	}
+
+	return Group{
+		Name:     "phoenix_variant_" + strings.ReplaceAll(variant, "-", "_"),
+		Interval: opts.Interval,
+		Rules: []Rule{
+			{
+				Record: sc.RuleCardinalityReduction,
+				Expr: fmt.Sprintf("(%s - %s) / %s * 100",
+					cardinality(opts.Baseline), cardinality(variant), cardinality(opts.Baseline)),
+				Labels: labels(sc.MetricTypeEfficiency),
+			},
+			{
+				Record: sc.RuleCriticalProcessCoverage,
+				Expr: fmt.Sprintf("%s / %s * 100",
+					executables(variant, critical), executables(opts.Baseline, critical)),
+				Labels: labels(sc.MetricTypeQuality),
+			},
+			{
+				// Share of baseline executables still visible in the variant,
+				// with critical processes weighted double
+				Record: sc.RuleSignalPreservation,
+				Expr: fmt.Sprintf("(%s / %s + 2 * (%s / %s)) / 3",
+					executables(variant), executables(opts.Baseline),
+					executables(variant, critical), executables(opts.Baseline, critical)),
+				Labels: labels(sc.MetricTypeQuality),
+			},
+			{
+				// 1 while the candidate is over the cardinality SLO threshold
+				Record: sc.RuleCardinalitySLOViolation,
+				Expr: fmt.Sprintf("%s > bool %g",
+					sc.SumBy(sc.Selector(sc.ProcessCardinality, sc.Eq(sc.LabelVariant, variant)), sc.LabelExperimentID, sc.LabelVariant),
+					opts.SLOThreshold),
+				Labels: labels(sc.MetricTypeQuality),
+			},
+		},
+	}
+}
+
+// sloWindows maps each burn-rate window to its error ratio recording rule
+var sloWindows = []struct {
+	window string
+	record string
+}{
+	{"5m", sc.RuleCardinalitySLOErrorRatio5m},
+	{"30m", sc.RuleCardinalitySLOErrorRatio30m},
+	{"1h", sc.RuleCardinalitySLOErrorRatio1h},
+	{"6h", sc.RuleCardinalitySLOErrorRatio6h},
+}
+
+// burnRateAlert fires when the error budget is being spent at least
+// burnRate times faster than sustainable over both the long and the short
+// window; the short window lets the alert resolve quickly once it stops
+type burnRateAlert struct {
+	name     string
+	long     string
+	short    string
+	burnRate float64
+	severity string
+}
+
+// burnRateAlerts use the usual thresholds for a 30 day budget: 14.4x over
+// 1h spends 2% of it, 6x over 6h spends 5%
+var burnRateAlerts = []burnRateAlert{
+	{name: "CardinalitySLOFastBurn", long: "1h", short: "5m", burnRate: 14.4, severity: "critical"},
+	{name: "CardinalitySLOSlowBurn", long: "6h", short: "30m", burnRate: 6, severity: "warning"},
+}
+
+// sloGroup records the cardinality SLO error ratio over every alert window
+func sloGroup(opts Options) Group {
+	group := Group{
+		Name:     "phoenix_cardinality_slo",
+		Interval: opts.Interval,
+	}
+	for _, w := range sloWindows {
+		group.Rules = append(group.Rules, Rule{
+			Record: w.record,
+			Expr:   fmt.Sprintf("avg_over_time(%s[%s])", sc.RuleCardinalitySLOViolation, w.window),
+			Labels: map[string]string{sc.LabelMetricType: sc.MetricTypeQuality},
+		})
+	}
+	return group
+}
+
+// GenerateAlerts builds the multi-window burn-rate alerts for the
+// cardinality SLO. They are written to their own file so that the recording
+// rules file holds recording rules only.
+func GenerateAlerts(opts Options) (*RuleFile, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	errorRatio := map[string]string{}
+	for _, w := range sloWindows {
+		errorRatio[w.window] = w.record
+	}
+
+	group := Group{
+		Name:     "phoenix_cardinality_slo_alerts",
+		Interval: opts.Interval,
+	}
+	budget := 1 - opts.SLOObjective
+	for _, a := range burnRateAlerts {
+		threshold := strconv.FormatFloat(a.burnRate*budget, 'g', 4, 64)
+		rule := Rule{
+			Alert: a.name,
+			Expr: fmt.Sprintf("%s > %s\nand\n%s > %s",
+				errorRatio[a.long], threshold, errorRatio[a.short], threshold),
+			Labels: map[string]string{
+				"severity":  a.severity,
+				"component": "experiment",
+			},
+			Annotations: map[string]string{
+				"summary": "Cardinality SLO error budget burning in experiment {{ $labels.experiment_id }}",
+				"description": fmt.Sprintf("Variant {{ $labels.variant }} is over %g time series often enough to spend its %.4g%% error budget %gx faster than sustainable (%s and %s windows)",
+					opts.SLOThreshold, budget*100, a.burnRate, a.long, a.short),
+				"runbook_url": "https://wiki.phoenix.io/runbooks/high-cardinality",
+			},
+		}
+		if opts.Environment != "" {
+			rule.Labels[sc.LabelEnvironment] = opts.Environment
+		}
+		group.Rules = append(group.Rules, rule)
+	}
+	return &RuleFile{Groups: []Group{group}}, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	sc "github.com/phoenix/platform/pkg/semconv"
+)
+
+func testOptions() Options {
+	return Options{
+		Baseline:     "baseline",
+		Variants:     []string{"candidate"},
+		Interval:     "30s",
+		CostPerGB:    0.25,
+		SLOThreshold: 25000,
+		SLOObjective: 0.99,
+	}
+}
+
+func TestBurnRateThresholds(t *testing.T) {
+	tests := []struct {
+		objective float64
+		fast      string
+		slow      string
+	}{
+		{0.99, "0.144", "0.06"},
+		{0.999, "0.0144", "0.006"},
+		{0.95, "0.72", "0.3"},
+	}
+
+	for _, tt := range tests {
+		opts := testOptions()
+		opts.SLOObjective = tt.objective
+		alerts, err := GenerateAlerts(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exprs := map[string]string{}
+		for _, g := range alerts.Groups {
+			for _, r := range g.Rules {
+				exprs[r.Alert] = r.Expr
+			}
+		}
+
+		want := map[string]string{
+			"CardinalitySLOFastBurn": sc.RuleCardinalitySLOErrorRatio1h + " > " + tt.fast + "\nand\n" + sc.RuleCardinalitySLOErrorRatio5m + " > " + tt.fast,
+			"CardinalitySLOSlowBurn": sc.RuleCardinalitySLOErrorRatio6h + " > " + tt.slow + "\nand\n" + sc.RuleCardinalitySLOErrorRatio30m + " > " + tt.slow,
+		}
+		for alert, expr := range want {
+			if exprs[alert] != expr {
+				t.Errorf("objective %g: %s = %q, want %q", tt.objective, alert, exprs[alert], expr)
+			}
+		}
+	}
+}
+
+func TestRecordingAndAlertingRulesAreSeparate(t *testing.T) {
+	opts := testOptions()
+	opts.Environment = "staging"
+
+	rules, err := Generate(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts, err := GenerateAlerts(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := map[string]bool{}
+	for _, g := range rules.Groups {
+		for _, r := range g.Rules {
+			if r.Alert != "" || r.Record == "" {
+				t.Errorf("recording rules file contains %+v", r)
+			}
+			records[r.Record] = true
+		}
+	}
+	for _, g := range alerts.Groups {
+		for _, r := range g.Rules {
+			if r.Record != "" || r.Alert == "" {
+				t.Errorf("alerts file contains %+v", r)
+			}
+			if r.Labels[sc.LabelEnvironment] != "staging" {
+				t.Errorf("alert %s has no environment label", r.Alert)
+			}
+		}
+	}
+
+	// Every error ratio an alert reads must be recorded
+	for _, w := range sloWindows {
+		if !records[w.record] {
+			t.Errorf("%s is not recorded", w.record)
+		}
+	}
+}
+
+func TestGenerateRejectsInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Options)
+		want   string
+	}{
+		{"no baseline", func(o *Options) { o.Baseline = "" }, "baseline"},
+		{"no variants", func(o *Options) { o.Variants = nil }, "variant"},
+		{"zero threshold", func(o *Options) { o.SLOThreshold = 0 }, "threshold"},
+		{"objective of one", func(o *Options) { o.SLOObjective = 1 }, "objective"},
+		{"objective as percent", func(o *Options) { o.SLOObjective = 99 }, "objective"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testOptions()
+			tt.modify(&opts)
+			for name, generate := range map[string]func(Options) (*RuleFile, error){"Generate": Generate, "GenerateAlerts": GenerateAlerts} {
+				if _, err := generate(opts); err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("%s: err = %v, want it to mention %q", name, err, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/phoenix/platform/pkg/config"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// migrateConfig is the configuration of "phoenix admin migrate". It talks
+// to the database directly, so it works while the API is down or runs with
+// DB_AUTO_MIGRATE=false.
+type migrateConfig struct {
+	DatabaseURL string `yaml:"database_url" env:"DATABASE_URL" flag:"database-url" usage:"PostgreSQL connection string" required:"true"`
+}
+
+// adminMigrate applies pending schema migrations, or lists applied, modified
+// and pending ones with "status"
+func adminMigrate(ctx context.Context, args []string) error {
+	var cfg migrateConfig
+	var rest []string
+	if err := config.Load(&cfg, config.WithArgs("phoenix admin migrate", args), config.WithRemainingArgs(&rest)); err != nil {
+		return err
+	}
+	action := "up"
+	if len(rest) > 1 {
+		return fmt.Errorf("usage: phoenix admin migrate [-database-url url] [up|status]")
+	}
+	if len(rest) == 1 {
+		action = rest[0]
+	}
+
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+
+		applied, err := store.Migrate(ctx, db)
+		for _, m := range applied {
+			fmt.Printf("applied   %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("database schema is up to date")
+		return nil
+
+	case "status":
+		applied, pending, err := store.MigrationStatus(ctx, db)
+		if errors.Is(err, store.ErrNoMigrationsTable) {
+			fmt.Println("no migrations table, the database has never been migrated")
+			pending, err = store.LoadMigrations()
+		}
+		if err != nil {
+			return err
+		}
+
+		checksums := make(map[int]string)
+		if all, err := store.LoadMigrations(); err == nil {
+			for _, m := range all {
+				checksums[m.Version] = m.Checksum()
+			}
+		}
+		for _, m := range applied {
+			state := "applied "
+			if want, ok := checksums[m.Version]; ok && m.Checksum != "" && m.Checksum != want {
+				state = "modified"
+			}
+			fmt.Printf("%s  %04d_%s  %s\n", state, m.Version, m.Name, m.AppliedAt.Format(time.RFC3339))
+		}
+		for _, m := range pending {
+			fmt.Printf("pending   %04d_%s\n", m.Version, m.Name)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown action %q, expected up or status", action)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+)
+
+// agentsList prints the collector inventory
+func agentsList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("agents list", flag.ContinueOnError)
+	req := &pb.ListAgentsRequest{}
+	fs.StringVar(&req.Status, "status", "", "only agents with this status: healthy or stale")
+	fs.StringVar(&req.Version, "version", "", "only agents running this collector version")
+	fs.StringVar(&req.PolicyHash, "policy-hash", "", "only agents running this policy")
+	fs.StringVar(&req.Mode, "mode", "", "only agents in this mode")
+	limit := fs.Int("limit", 100, "maximum number of agents to list")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: phoenix agents list [-status healthy|stale] [-version v] [-policy-hash h] [-mode m] [-limit n]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	req.Limit = int32(*limit)
+
+	c, err := connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := c.ListAgents(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tHOSTNAME\tVERSION\tMODE\tPOLICY\tSTATUS\tCARDINALITY\tLAST SEEN")
+	for _, a := range resp.Agents {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			a.Id, a.Hostname, a.Version, a.Mode, a.PolicyHash, a.Status, a.CardinalityEstimate, a.LastSeen.AsTime().Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if int(resp.Total) > len(resp.Agents) {
+		fmt.Fprintf(os.Stderr, "showing %d of %d agents\n", len(resp.Agents), resp.Total)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// experimentArtifacts lists the artifacts of an experiment, or writes them
+// to a directory with -output after checking their digests
+func experimentArtifacts(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("experiment artifacts", flag.ContinueOnError)
+	variant := fs.String("variant", "", "only the artifacts of this variant")
+	output := fs.String("output", "", "write the artifacts to this directory instead of listing them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: phoenix experiment artifacts [-variant name] [-output dir] <experiment-id>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one experiment id")
+	}
+	experimentID := fs.Arg(0)
+
+	c, err := connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	artifacts, err := c.GetExperimentArtifacts(ctx, experimentID, *variant)
+	if err != nil {
+		return err
+	}
+	if len(artifacts) == 0 {
+		return fmt.Errorf("experiment %s has no artifacts", experimentID)
+	}
+
+	if *output == "" {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VARIANT\tKIND\tNAME\tSIZE\tSHA256\tCREATED")
+		for _, a := range artifacts {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+				a.Variant, a.Kind, a.Name, len(a.Content), a.Sha256, a.CreatedAt.AsTime().Format(time.RFC3339))
+		}
+		return w.Flush()
+	}
+
+	dir := filepath.Join(*output, experimentID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, a := range artifacts {
+		sum := sha256.Sum256(a.Content)
+		if a.Sha256 != "" && hex.EncodeToString(sum[:]) != a.Sha256 {
+			return fmt.Errorf("artifact %s: content does not match its sha256", a.Name)
+		}
+		// Names are generated by the API but still must not escape dir
+		name := filepath.Base(a.Name)
+		if err := os.WriteFile(filepath.Join(dir, name), a.Content, 0o644); err != nil {
+			return err
+		}
+		fmt.Println(filepath.Join(dir, name))
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/phoenix/platform/pkg/client"
+)
+
+const defaultAPIAddr = "localhost:5050"
+
+// connect dials the platform API configured by the environment
+func connect() (*client.Client, error) {
+	addr := os.Getenv("PHOENIX_API_ADDR")
+	if addr == "" {
+		addr = defaultAPIAddr
+	}
+
+	var opts []client.Option
+	if token := os.Getenv("PHOENIX_TOKEN"); token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	if insecure, _ := strconv.ParseBool(os.Getenv("PHOENIX_API_INSECURE")); insecure {
+		opts = append(opts, client.WithInsecure())
+	}
+	return client.New(addr, opts...)
+}
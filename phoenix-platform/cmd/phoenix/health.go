@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthCheck asks the API's grpc.health.v1 service for its overall status,
+// or for the named dependencies such as store or prometheus, and fails
+// unless all of them are serving
+func healthCheck(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 5*time.Second, "how long to wait for each answer")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: phoenix health [-timeout d] [dependency...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	services := fs.Args()
+	if len(services) == 0 {
+		services = []string{""}
+	}
+
+	c, err := connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tSTATUS")
+	unhealthy := 0
+	for _, service := range services {
+		checkCtx, cancel := context.WithTimeout(ctx, *timeout)
+		status, err := c.Health(checkCtx, service)
+		cancel()
+
+		name := service
+		if name == "" {
+			name = "(overall)"
+		}
+		result := status.String()
+		if err != nil {
+			result = err.Error()
+		}
+		if status != healthpb.HealthCheckResponse_SERVING {
+			unhealthy++
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, result)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d not serving", unhealthy, len(services))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// phoenix is the command line client of the platform API.
+//
+//	phoenix experiment artifacts [-variant name] [-output dir] <experiment-id>
+//	phoenix experiment approve|reject [-comment text] <experiment-id>
+//	phoenix agents list [-status healthy|stale] [-version v] [-limit n]
+//	phoenix admin migrate [-database-url url] [up|status]
+//	phoenix health [dependency...]
+//	phoenix pipeline render [-template name] [-spec file -variant name] [-set NAME=VALUE]...
+//
+// The API is reached over gRPC at PHOENIX_API_ADDR (default localhost:5050)
+// with the bearer token in PHOENIX_TOKEN. Set PHOENIX_API_INSECURE=true to
+// connect without TLS, e.g. to a local API server. Admin commands connect to
+// the database at DATABASE_URL instead.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+// command runs one subcommand with the arguments following its name
+type command func(ctx context.Context, args []string) error
+
+// commands maps each command group to its subcommands; a group without
+// subcommands is a single command under the empty name
+var commands = map[string]map[string]command{
+	"admin": {
+		"migrate": adminMigrate,
+	},
+	"agents": {
+		"list": agentsList,
+	},
+	"experiment": {
+		"approve":   experimentApprove,
+		"artifacts": experimentArtifacts,
+		"reject":    experimentReject,
+	},
+	"health": {
+		"": healthCheck,
+	},
+	"pipeline": {
+		"render": pipelineRender,
+	},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: phoenix <command> <subcommand> [flags]
+
+commands:
+  admin migrate          apply or list database schema migrations
+  agents list            list the collector agents and their health
+  experiment approve     approve a proposed experiment
+  experiment artifacts   list or download the rendered artifacts of an experiment
+  experiment reject      reject a proposed experiment
+  health                 check that the API and its dependencies are serving
+  pipeline render        print the collector config a variant is deployed with`)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return 0
+	}
+
+	group, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "phoenix: unknown command %q\n", args[0])
+		usage()
+		return 2
+	}
+	name, cmd, rest := args[0], group[""], args[1:]
+	if cmd == nil {
+		if len(args) < 2 || group[args[1]] == nil {
+			names := make([]string, 0, len(group))
+			for sub := range group {
+				names = append(names, sub)
+			}
+			sort.Strings(names)
+			fmt.Fprintf(os.Stderr, "phoenix %s: expected one of %v\n", args[0], names)
+			return 2
+		}
+		name, cmd, rest = args[0]+" "+args[1], group[args[1]], args[2:]
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := cmd(ctx, rest); err != nil {
+		fmt.Fprintf(os.Stderr, "phoenix %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
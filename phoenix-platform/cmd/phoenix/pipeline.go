@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+
+	"github.com/phoenix/platform/pipelines"
+	pb "github.com/phoenix/platform/pkg/api/v1"
+)
+
+// placeholder matches ${NAME} and ${NAME:-default} in rendered configs
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// assignments collects repeated -set NAME=VALUE flags
+type assignments map[string]string
+
+func (a assignments) String() string { return "" }
+
+func (a assignments) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected NAME=VALUE, got %q", s)
+	}
+	a[name] = value
+	return nil
+}
+
+// pipelineRender prints the collector config the platform deploys for a
+// variant, rendered locally with the same renderer as the API. The API
+// renders every variant from the baseline template; -template previews
+// another catalog template.
+func pipelineRender(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("pipeline render", flag.ContinueOnError)
+	template := fs.String("template", pipelines.Baseline, "catalog template to render")
+	specFile := fs.String("spec", "", "experiment spec in JSON, as sent to the API")
+	variant := fs.String("variant", "", "variant of -spec whose visual pipeline is rendered")
+	output := fs.String("output", "", "write the config to this file instead of stdout")
+	set := assignments{}
+	fs.Var(set, "set", "fill in a placeholder, e.g. PHOENIX_VARIANT=candidate (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: phoenix pipeline render [-template name] [-spec file -variant name] [-set NAME=VALUE]... [-output file]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	if (*specFile == "") != (*variant == "") {
+		return fmt.Errorf("-spec and -variant must be given together")
+	}
+
+	var pipeline *pb.VisualPipeline
+	if *specFile != "" {
+		var err error
+		if pipeline, err = variantPipeline(*specFile, *variant); err != nil {
+			return err
+		}
+	}
+
+	config, err := pipelines.Render(*template, pipeline)
+	if err != nil {
+		return err
+	}
+	rendered := placeholder.ReplaceAllStringFunc(string(config), func(m string) string {
+		if value, ok := set[placeholder.FindStringSubmatch(m)[1]]; ok {
+			return value
+		}
+		return m
+	})
+
+	// The values set must still leave valid YAML
+	var check map[string]interface{}
+	if err := yaml.Unmarshal([]byte(rendered), &check); err != nil {
+		return fmt.Errorf("rendered config is invalid: %w", err)
+	}
+	if unset := unsetPlaceholders(rendered); len(unset) > 0 {
+		fmt.Fprintf(os.Stderr, "left for the collector's environment: %s\n", strings.Join(unset, ", "))
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.WriteString(rendered)
+		return err
+	}
+	return os.WriteFile(*output, []byte(rendered), 0o644)
+}
+
+// variantPipeline reads the visual pipeline of one variant of a spec file
+func variantPipeline(path, variant string) (*pb.VisualPipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &pb.ExperimentSpec{}
+	if err := protojson.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, v := range spec.Variants {
+		if v.Name == variant {
+			return v.Pipeline, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no variant %q", path, variant)
+}
+
+// unsetPlaceholders lists the placeholders without a default that remain
+func unsetPlaceholders(config string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range placeholder.FindAllStringSubmatch(config, -1) {
+		if m[2] == "" && !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
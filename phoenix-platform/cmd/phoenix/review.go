@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/client"
+)
+
+// experimentApprove approves a proposed experiment so it starts running
+func experimentApprove(ctx context.Context, args []string) error {
+	return reviewExperiment(ctx, "approve", args, (*client.Client).ApproveExperiment)
+}
+
+// experimentReject rejects a proposed experiment
+func experimentReject(ctx context.Context, args []string) error {
+	return reviewExperiment(ctx, "reject", args, (*client.Client).RejectExperiment)
+}
+
+func reviewExperiment(ctx context.Context, verb string, args []string, review func(*client.Client, context.Context, string, string) (*pb.Experiment, error)) error {
+	fs := flag.NewFlagSet("experiment "+verb, flag.ContinueOnError)
+	comment := fs.String("comment", "", "reason recorded with the review")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: phoenix experiment %s [-comment text] <experiment-id>\n", verb)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one experiment id")
+	}
+
+	c, err := connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	exp, err := review(c, ctx, fs.Arg(0), *comment)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s: %s\n", exp.Id, exp.Status.GetPhase(), exp.Status.GetMessage())
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// simulatorConfig is loaded from CONFIG_FILE (or -config), the environment and flags
+type simulatorConfig struct {
+	Profile        string        `yaml:"profile" env:"PROFILE" flag:"profile" usage:"simulation profile"`
+	Duration       time.Duration `yaml:"duration" env:"DURATION" flag:"duration" usage:"how long to run"`
+	ProcessCount   int           `yaml:"process_count" env:"PROCESS_COUNT" flag:"process-count" usage:"number of simulated processes"`
+	Mode           string        `yaml:"mode" env:"SIMULATOR_MODE" flag:"mode" usage:"process or otlp"`
+	ExportInterval time.Duration `yaml:"export_interval" env:"EXPORT_INTERVAL" flag:"export-interval" usage:"OTLP export interval"`
+	Seed           int64         `yaml:"seed" env:"SEED" flag:"seed" usage:"random seed, 0 picks a time-based seed"`
+	ScenarioFile   string        `yaml:"scenario_file" env:"SCENARIO_FILE" flag:"scenario" usage:"recorded scenario to replay"`
+}
+
+func defaultSimulatorConfig() simulatorConfig {
+	return simulatorConfig{
+		Profile:        "realistic",
+		Duration:       time.Hour,
+		ProcessCount:   100,
+		Mode:           ModeProcess,
+		ExportInterval: 10 * time.Second,
+	}
+}
+
+func (c *simulatorConfig) Validate() []string {
+	var problems []string
+	if _, ok := profiles[c.Profile]; !ok {
+		problems = append(problems, fmt.Sprintf("profile: unknown profile %q", c.Profile))
+	}
+	if c.Duration <= 0 {
+		problems = append(problems, "duration: must be positive")
+	}
+	if c.ProcessCount <= 0 {
+		problems = append(problems, "process_count: must be positive")
+	}
+	if c.Mode != ModeProcess && c.Mode != ModeOTLP {
+		problems = append(problems, fmt.Sprintf("mode: must be %s or %s, got %q", ModeProcess, ModeOTLP, c.Mode))
+	}
+	if c.ExportInterval <= 0 {
+		problems = append(problems, "export_interval: must be positive")
+	}
+	return problems
+}
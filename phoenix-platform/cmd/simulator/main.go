@@ -7,22 +7,29 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
-	"strconv"
+	"sort"
 	"sync"
 	"syscall"
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/phoenix/platform/pkg/config"
 )
 
 type ProcessSimulator struct {
-	profile      string
-	processCount int
-	duration     time.Duration
-	processes    map[string]*SimulatedProcess
-	mu           sync.RWMutex
-	logger       *zap.Logger
-	startTime    time.Time
+	profile        string
+	mode           string
+	processCount   int
+	duration       time.Duration
+	exportInterval time.Duration
+	processes      map[string]*SimulatedProcess
+	nextPID        int
+	scenario       *Scenario
+	rng            *rand.Rand
+	mu             sync.RWMutex
+	logger         *zap.Logger
+	startTime      time.Time
 }
 
 type SimulatedProcess struct {
@@ -33,6 +40,14 @@ type SimulatedProcess struct {
 	StartTime  time.Time
 	Lifetime   time.Duration
 	cmd        *exec.Cmd
+
+	// Synthetic usage, only tracked in OTLP mode
+	cpuSeconds float64
+	memBytes   int64
+
+	// Usage from the current scenario sample, only set when replaying
+	recordedCPU int
+	recordedMem int64
 }
 
 type Profile struct {
@@ -88,36 +103,40 @@ func main() {
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
 
-	// Parse environment variables
-	profile := os.Getenv("PROFILE")
-	if profile == "" {
-		profile = "realistic"
+	if len(os.Args) > 1 && os.Args[1] == "record" {
+		os.Exit(recordCommand(os.Args[2:], logger))
 	}
 
-	duration := os.Getenv("DURATION")
-	if duration == "" {
-		duration = "1h"
+	cfg := defaultSimulatorConfig()
+	if err := config.Load(&cfg, config.WithFile(os.Getenv("CONFIG_FILE")), config.WithArgs("simulator", os.Args[1:])); err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
 	}
 
-	processCount := 100
-	if pc := os.Getenv("PROCESS_COUNT"); pc != "" {
-		if n, err := strconv.Atoi(pc); err == nil {
-			processCount = n
-		}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
 
-	dur, err := time.ParseDuration(duration)
-	if err != nil {
-		logger.Fatal("Invalid duration", zap.Error(err))
+	var scenario *Scenario
+	if cfg.ScenarioFile != "" {
+		var err error
+		if scenario, err = LoadScenario(cfg.ScenarioFile); err != nil {
+			logger.Fatal("Failed to load scenario", zap.Error(err))
+		}
 	}
 
 	simulator := &ProcessSimulator{
-		profile:      profile,
-		processCount: processCount,
-		duration:     dur,
-		processes:    make(map[string]*SimulatedProcess),
-		logger:       logger,
-		startTime:    time.Now(),
+		profile:        cfg.Profile,
+		mode:           cfg.Mode,
+		processCount:   cfg.ProcessCount,
+		duration:       cfg.Duration,
+		exportInterval: cfg.ExportInterval,
+		processes:      make(map[string]*SimulatedProcess),
+		nextPID:        syntheticPIDBase,
+		scenario:       scenario,
+		rng:            rand.New(newLockedSource(seed)),
+		logger:         logger,
+		startTime:      time.Now(),
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -142,9 +161,28 @@ func main() {
 func (s *ProcessSimulator) Run(ctx context.Context) error {
 	s.logger.Info("Starting process simulation",
 		zap.String("profile", s.profile),
+		zap.String("mode", s.mode),
 		zap.Int("processCount", s.processCount),
 		zap.Duration("duration", s.duration))
 
+	if s.mode == ModeOTLP {
+		shutdown, err := s.startOTLPReporter(ctx, s.exportInterval)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := shutdown(shutdownCtx); err != nil {
+				s.logger.Warn("Failed to flush OTLP metrics", zap.Error(err))
+			}
+		}()
+	}
+
+	if s.scenario != nil {
+		return s.runReplay(ctx)
+	}
+
 	// Load profile
 	profile, ok := profiles[s.profile]
 	if !ok {
@@ -167,6 +205,9 @@ func (s *ProcessSimulator) Run(ctx context.Context) error {
 	for {
 		select {
 		case <-ticker.C:
+			if s.mode == ModeOTLP {
+				s.updateSyntheticUsage(time.Second)
+			}
 			s.updateProcesses()
 			s.checkLifetimes(profile)
 
@@ -208,7 +249,7 @@ func (s *ProcessSimulator) startInitialProcesses(profile *Profile) error {
 			processIdx++
 			
 			// Stagger process creation
-			time.Sleep(time.Duration(rand.Intn(100)) * time.Millisecond)
+			time.Sleep(time.Duration(s.rng.Intn(100)) * time.Millisecond)
 		}
 	}
 
@@ -220,7 +261,7 @@ func (s *ProcessSimulator) createProcess(pattern ProcessPattern, index int) *Sim
 	name := fmt.Sprintf(pattern.NameTemplate, index)
 	if len(name) > 2 && name[len(name)-2:] == "%!" {
 		// Handle templates with multiple placeholders
-		name = fmt.Sprintf(pattern.NameTemplate, randomString(6), index)
+		name = fmt.Sprintf(pattern.NameTemplate, randomString(s.rng, 6), index)
 	}
 
 	lifetime := pattern.Lifetime
@@ -238,12 +279,21 @@ func (s *ProcessSimulator) createProcess(pattern ProcessPattern, index int) *Sim
 }
 
 func (s *ProcessSimulator) startProcess(proc *SimulatedProcess) error {
+	if s.mode == ModeOTLP {
+		s.mu.Lock()
+		s.nextPID++
+		proc.PID = s.nextPID
+		s.processes[proc.Name] = proc
+		s.mu.Unlock()
+		return nil
+	}
+
 	// Use stress-ng to simulate CPU and memory usage
 	args := []string{
 		"--cpu", "1",
-		"--cpu-load", s.getCPULoad(proc.CPUPattern),
+		"--cpu-load", s.getCPULoad(proc),
 		"--vm", "1",
-		"--vm-bytes", s.getMemorySize(proc.MemPattern),
+		"--vm-bytes", s.getMemorySize(proc),
 		"--timeout", "0", // Run indefinitely
 		"--metrics-brief",
 	}
@@ -286,41 +336,45 @@ func (s *ProcessSimulator) startProcess(proc *SimulatedProcess) error {
 	return nil
 }
 
-func (s *ProcessSimulator) getCPULoad(pattern string) string {
+func (s *ProcessSimulator) getCPULoad(proc *SimulatedProcess) string {
 	elapsed := time.Since(s.startTime)
 	
-	switch pattern {
+	switch proc.CPUPattern {
 	case "steady":
 		return "20"
 	case "spiky":
 		// Varies between 10-80%
-		return fmt.Sprintf("%d", 10+rand.Intn(70))
+		return fmt.Sprintf("%d", 10+s.rng.Intn(70))
 	case "growing":
 		// Increases over time
 		growth := int(elapsed.Minutes())
 		return fmt.Sprintf("%d", min(80, 10+growth))
 	case "random":
-		return fmt.Sprintf("%d", rand.Intn(100))
+		return fmt.Sprintf("%d", s.rng.Intn(100))
+	case patternRecorded:
+		return fmt.Sprintf("%d", min(100, proc.recordedCPU))
 	default:
 		return "20"
 	}
 }
 
-func (s *ProcessSimulator) getMemorySize(pattern string) string {
+func (s *ProcessSimulator) getMemorySize(proc *SimulatedProcess) string {
 	elapsed := time.Since(s.startTime)
 	
-	switch pattern {
+	switch proc.MemPattern {
 	case "steady":
 		return "50M"
 	case "spiky":
 		// Varies between 20MB-200MB
-		return fmt.Sprintf("%dM", 20+rand.Intn(180))
+		return fmt.Sprintf("%dM", 20+s.rng.Intn(180))
 	case "growing":
 		// Increases over time
 		growth := int(elapsed.Minutes()) * 5
 		return fmt.Sprintf("%dM", min(500, 50+growth))
 	case "random":
-		return fmt.Sprintf("%dM", 10+rand.Intn(200))
+		return fmt.Sprintf("%dM", 10+s.rng.Intn(200))
+	case patternRecorded:
+		return fmt.Sprintf("%dM", max(1, int(proc.recordedMem>>20)))
 	default:
 		return "50M"
 	}
@@ -331,7 +385,7 @@ func (s *ProcessSimulator) updateProcesses() {
 	activeCount := len(s.processes)
 	s.mu.RUnlock()
 
-	if activeCount > 0 && rand.Float64() < 0.01 { // 1% chance per second
+	if activeCount > 0 && s.rng.Float64() < 0.01 { // 1% chance per second
 		// Log current state
 		s.logger.Info("Process simulator status",
 			zap.Int("activeProcesses", activeCount),
@@ -343,7 +397,8 @@ func (s *ProcessSimulator) checkLifetimes(profile *Profile) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for name, proc := range s.processes {
+	for _, name := range s.sortedNames() {
+		proc := s.processes[name]
 		if proc.Lifetime > 0 && time.Since(proc.StartTime) > proc.Lifetime {
 			s.logger.Debug("Process lifetime expired",
 				zap.String("name", name),
@@ -355,7 +410,7 @@ func (s *ProcessSimulator) checkLifetimes(profile *Profile) {
 			// Start a replacement
 			for _, pattern := range profile.Patterns {
 				if matchesPattern(name, pattern.NameTemplate) {
-					newProc := s.createProcess(pattern, rand.Intn(1000))
+					newProc := s.createProcess(pattern, s.rng.Intn(1000))
 					go s.startProcess(newProc)
 					break
 				}
@@ -380,13 +435,10 @@ func (s *ProcessSimulator) simulateChurn(profile *Profile) {
 		zap.Float64("rate", profile.ChurnRate))
 
 	// Select random processes to restart
-	names := make([]string, 0, processCount)
-	for name := range s.processes {
-		names = append(names, name)
-	}
+	names := s.sortedNames()
 
 	for i := 0; i < churns && i < len(names); i++ {
-		idx := rand.Intn(len(names))
+		idx := s.rng.Intn(len(names))
 		name := names[idx]
 		proc := s.processes[name]
 		
@@ -397,7 +449,7 @@ func (s *ProcessSimulator) simulateChurn(profile *Profile) {
 			// Start a replacement
 			for _, pattern := range profile.Patterns {
 				if matchesPattern(name, pattern.NameTemplate) {
-					newProc := s.createProcess(pattern, rand.Intn(1000))
+					newProc := s.createProcess(pattern, s.rng.Intn(1000))
 					go s.startProcess(newProc)
 					break
 				}
@@ -442,6 +494,17 @@ func (s *ProcessSimulator) cleanup() error {
 	return nil
 }
 
+// sortedNames returns process names in a stable order so that seeded runs
+// make the same choices. Callers must hold s.mu.
+func (s *ProcessSimulator) sortedNames() []string {
+	names := make([]string, 0, len(s.processes))
+	for name := range s.processes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -449,11 +512,18 @@ func min(a, b int) int {
 	return b
 }
 
-func randomString(length int) string {
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func randomString(rng *rand.Rand, length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
 	b := make([]byte, length)
 	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+		b[i] = charset[rng.Intn(len(charset))]
 	}
 	return string(b)
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/zap"
+
+	sc "github.com/phoenix/platform/pkg/semconv"
+)
+
+const (
+	// ModeProcess spawns real stress-ng (or shell) processes
+	ModeProcess = "process"
+	// ModeOTLP keeps the population in memory and reports it as OTLP metrics,
+	// using the same metric and attribute names as the hostmetrics process scraper
+	ModeOTLP = "otlp"
+
+	syntheticPIDBase = 100000
+)
+
+// startOTLPReporter registers observable instruments for the simulated
+// population and starts a periodic OTLP exporter. The returned function
+// flushes and stops the exporter.
+func (s *ProcessSimulator) startOTLPReporter(ctx context.Context, interval time.Duration) (func(context.Context) error, error) {
+	exporter, err := otlpmetricgrpc.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String(sc.AttrServiceName, "phoenix-process-simulator"),
+		attribute.String(sc.AttrHostName, hostname),
+		attribute.String(sc.AttrSimulatorProfile, s.profile),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))),
+	)
+	meter := provider.Meter("github.com/phoenix/platform/cmd/simulator")
+
+	cpuTime, err := meter.Float64ObservableCounter(sc.OTelProcessCPUTime,
+		metric.WithUnit("s"),
+		metric.WithDescription("Total CPU seconds of the simulated process"))
+	if err != nil {
+		return nil, err
+	}
+	memPhysical, err := meter.Int64ObservableGauge(sc.OTelProcessMemoryPhysical,
+		metric.WithUnit("By"),
+		metric.WithDescription("Resident memory of the simulated process"))
+	if err != nil {
+		return nil, err
+	}
+	memVirtual, err := meter.Int64ObservableGauge(sc.OTelProcessMemoryVirtual,
+		metric.WithUnit("By"),
+		metric.WithDescription("Virtual memory of the simulated process"))
+	if err != nil {
+		return nil, err
+	}
+	processCount, err := meter.Int64ObservableGauge(sc.OTelSimulatorProcesses,
+		metric.WithDescription("Ground truth number of simulated processes"))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		for _, proc := range s.processes {
+			attrs := metric.WithAttributes(
+				attribute.Int(sc.AttrProcessPID, proc.PID),
+				attribute.String(sc.AttrProcessExecutableName, executableName(proc.Name)),
+				attribute.String(sc.AttrProcessCommandLine, proc.Name),
+			)
+			o.ObserveFloat64(cpuTime, proc.cpuSeconds, attrs, metric.WithAttributes(attribute.String(sc.AttrState, "user")))
+			o.ObserveInt64(memPhysical, proc.memBytes, attrs)
+			o.ObserveInt64(memVirtual, proc.memBytes*2, attrs)
+		}
+		o.ObserveInt64(processCount, int64(len(s.processes)),
+			metric.WithAttributes(attribute.String(sc.AttrSimulatorProfile, s.profile)))
+		return nil
+	}, cpuTime, memPhysical, memVirtual, processCount)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Reporting simulated processes over OTLP", zap.Duration("interval", interval))
+	return provider.Shutdown, nil
+}
+
+// updateSyntheticUsage advances the in-memory CPU and memory counters of every
+// simulated process by one tick
+func (s *ProcessSimulator) updateSyntheticUsage(tick time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range s.sortedNames() {
+		proc := s.processes[name]
+		load, _ := strconv.Atoi(s.getCPULoad(proc))
+		proc.cpuSeconds += tick.Seconds() * float64(load) / 100
+		if proc.MemPattern == patternRecorded {
+			proc.memBytes = proc.recordedMem
+			continue
+		}
+		proc.memBytes = parseMemorySize(s.getMemorySize(proc))
+	}
+}
+
+// executableName derives a stable executable name from the templated process
+// name, e.g. "nginx-worker-3" -> "nginx-worker"
+func executableName(name string) string {
+	parts := strings.Split(name, "-")
+	for len(parts) > 1 {
+		if _, err := strconv.Atoi(parts[len(parts)-1]); err != nil {
+			break
+		}
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, "-")
+}
+
+// parseMemorySize converts the "<n>M" sizes used for stress-ng into bytes
+func parseMemorySize(size string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSuffix(size, "M"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n * 1024 * 1024
+}
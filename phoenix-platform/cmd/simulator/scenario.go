@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// patternRecorded marks processes whose usage comes from a scenario file
+	patternRecorded = "recorded"
+
+	// clockTicks is USER_HZ, the unit of utime/stime in /proc/<pid>/stat
+	clockTicks = 100
+)
+
+// Scenario is a recorded process table over time
+type Scenario struct {
+	Host     string           `json:"host"`
+	Recorded time.Time        `json:"recorded"`
+	Interval time.Duration    `json:"interval"`
+	Samples  []ScenarioSample `json:"samples"`
+}
+
+// ScenarioSample is the process table at Offset from the start of recording
+type ScenarioSample struct {
+	Offset    time.Duration     `json:"offset"`
+	Processes []RecordedProcess `json:"processes"`
+}
+
+// RecordedProcess is one process as observed in /proc
+type RecordedProcess struct {
+	PID        int    `json:"pid"`
+	Name       string `json:"name"`
+	CPUPercent int    `json:"cpu_percent"`
+	MemBytes   int64  `json:"mem_bytes"`
+}
+
+// key identifies the same recorded process across samples
+func (p RecordedProcess) key() string {
+	return fmt.Sprintf("%s-%d", p.Name, p.PID)
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario := &Scenario{}
+	if err := json.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if len(scenario.Samples) == 0 {
+		return nil, fmt.Errorf("scenario %s has no samples", path)
+	}
+	return scenario, nil
+}
+
+// recordCommand implements "process-simulator record", sampling the local
+// process table from /proc into a scenario file
+func recordCommand(args []string, logger *zap.Logger) int {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	output := fs.String("output", "scenario.json", "scenario file to write")
+	interval := fs.Duration("interval", 5*time.Second, "sampling interval")
+	duration := fs.Duration("duration", 10*time.Minute, "recording duration")
+	procRoot := fs.String("proc", "/proc", "proc filesystem root")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+
+	hostname, _ := os.Hostname()
+	scenario := &Scenario{
+		Host:     hostname,
+		Recorded: time.Now().UTC(),
+		Interval: *interval,
+	}
+
+	logger.Info("Recording process table",
+		zap.String("output", *output),
+		zap.Duration("interval", *interval),
+		zap.Duration("duration", *duration))
+
+	start := time.Now()
+	prevTicks := map[int]uint64{}
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		sample, ticks, err := sampleProcTable(*procRoot, prevTicks, *interval)
+		if err != nil {
+			logger.Error("Failed to sample process table", zap.Error(err))
+			return 1
+		}
+		sample.Offset = time.Since(start).Truncate(time.Millisecond)
+		scenario.Samples = append(scenario.Samples, sample)
+		prevTicks = ticks
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+
+	data, err := json.MarshalIndent(scenario, "", "  ")
+	if err != nil {
+		logger.Error("Failed to encode scenario", zap.Error(err))
+		return 1
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		logger.Error("Failed to write scenario", zap.Error(err))
+		return 1
+	}
+
+	logger.Info("Scenario recorded", zap.Int("samples", len(scenario.Samples)))
+	return 0
+}
+
+// sampleProcTable reads name, CPU and resident memory for every process.
+// CPU is derived from the utime+stime delta since the previous sample.
+func sampleProcTable(procRoot string, prevTicks map[int]uint64, interval time.Duration) (ScenarioSample, map[int]uint64, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return ScenarioSample{}, nil, err
+	}
+
+	pageSize := int64(os.Getpagesize())
+	ticks := make(map[int]uint64)
+	sample := ScenarioSample{}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(procRoot, entry.Name())
+
+		// Processes can exit between listing and reading; skip them
+		comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+		if err != nil {
+			continue
+		}
+		total, err := readCPUTicks(filepath.Join(dir, "stat"))
+		if err != nil {
+			continue
+		}
+		resident, err := readResidentPages(filepath.Join(dir, "statm"))
+		if err != nil {
+			continue
+		}
+
+		ticks[pid] = total
+		cpu := 0
+		if prev, ok := prevTicks[pid]; ok && total >= prev {
+			cpu = int(float64(total-prev) / clockTicks / interval.Seconds() * 100)
+		}
+
+		sample.Processes = append(sample.Processes, RecordedProcess{
+			PID:        pid,
+			Name:       strings.TrimSpace(string(comm)),
+			CPUPercent: cpu,
+			MemBytes:   resident * pageSize,
+		})
+	}
+
+	sort.Slice(sample.Processes, func(i, j int) bool {
+		return sample.Processes[i].PID < sample.Processes[j].PID
+	})
+	return sample, ticks, nil
+}
+
+func readCPUTicks(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// The command name is parenthesised and may contain spaces; fields
+	// after the closing paren start at state (field 3)
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("malformed stat file %s", path)
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("malformed stat file %s", path)
+	}
+
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return utime + stime, nil
+}
+
+func readResidentPages(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Split(bufio.ScanWords)
+	// statm: size resident shared text lib data dt
+	for i := 0; scanner.Scan(); i++ {
+		if i == 1 {
+			return strconv.ParseInt(scanner.Text(), 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("malformed statm file %s", path)
+}
+
+// runReplay drives the simulated population from a recorded scenario. The
+// scenario loops until the simulation duration is reached.
+func (s *ProcessSimulator) runReplay(ctx context.Context) error {
+	s.logger.Info("Replaying scenario",
+		zap.String("host", s.scenario.Host),
+		zap.Int("samples", len(s.scenario.Samples)))
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	timeout := time.After(s.duration)
+	length := s.scenario.Samples[len(s.scenario.Samples)-1].Offset + s.scenario.Interval
+	loopStart := time.Now()
+	next := 0
+
+	for {
+		for next < len(s.scenario.Samples) && time.Since(loopStart) >= s.scenario.Samples[next].Offset {
+			s.applySample(s.scenario.Samples[next])
+			next++
+		}
+		if next == len(s.scenario.Samples) && time.Since(loopStart) >= length {
+			loopStart = loopStart.Add(length)
+			next = 0
+		}
+
+		select {
+		case <-ticker.C:
+			if s.mode == ModeOTLP {
+				s.updateSyntheticUsage(time.Second)
+			}
+			s.updateProcesses()
+
+		case <-timeout:
+			s.logger.Info("Simulation duration reached")
+			return s.cleanup()
+
+		case <-ctx.Done():
+			s.logger.Info("Context cancelled")
+			return s.cleanup()
+		}
+	}
+}
+
+// applySample reconciles the running population with one recorded sample:
+// processes that disappeared are stopped, new ones are started and usage of
+// surviving ones is updated
+func (s *ProcessSimulator) applySample(sample ScenarioSample) {
+	want := make(map[string]RecordedProcess, len(sample.Processes))
+	for _, p := range sample.Processes {
+		if len(want) >= s.processCount {
+			break
+		}
+		want[p.key()] = p
+	}
+
+	s.mu.Lock()
+	var toStart []*SimulatedProcess
+	for _, name := range s.sortedNames() {
+		if _, ok := want[name]; !ok {
+			s.stopProcess(s.processes[name])
+			delete(s.processes, name)
+		}
+	}
+	for key, p := range want {
+		if proc, ok := s.processes[key]; ok {
+			proc.recordedCPU = p.CPUPercent
+			proc.recordedMem = p.MemBytes
+			continue
+		}
+		toStart = append(toStart, &SimulatedProcess{
+			Name:        key,
+			CPUPattern:  patternRecorded,
+			MemPattern:  patternRecorded,
+			StartTime:   time.Now(),
+			recordedCPU: p.CPUPercent,
+			recordedMem: p.MemBytes,
+		})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(toStart, func(i, j int) bool { return toStart[i].Name < toStart[j].Name })
+	for _, proc := range toStart {
+		if err := s.startProcess(proc); err != nil {
+			s.logger.Warn("Failed to start process",
+				zap.String("name", proc.Name),
+				zap.Error(err))
+		}
+	}
+}
+
+// lockedSource makes a seeded rand.Source safe for the simulator's goroutines
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func newLockedSource(seed int64) *lockedSource {
+	return &lockedSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (l *lockedSource) Int63() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.src.Int63()
+}
+
+func (l *lockedSource) Uint64() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.src.Uint64()
+}
+
+func (l *lockedSource) Seed(seed int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.src.Seed(seed)
+}
@@ -10,7 +10,7 @@ type PhoenixProcessPipelineSpec struct {
 	// ExperimentID is the ID of the experiment this pipeline belongs to
 	ExperimentID string `json:"experimentID"`
 
-	// Variant is either "baseline" or "candidate"
+	// Variant is "baseline" or the name of a candidate
 	Variant string `json:"variant"`
 
 	// ConfigMap is the name of the ConfigMap containing the OTel collector configuration
@@ -24,6 +24,11 @@ type PhoenixProcessPipelineSpec struct {
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 
+	// Nodes restricts the collector to these hosts so that each variant of
+	// an experiment runs on its own node subset
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+
 	// Tolerations for pod assignment
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
@@ -7,6 +7,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -305,6 +306,27 @@ func (r *PipelineReconciler) buildDaemonSet(pipeline *phoenixv1alpha1.PhoenixPro
 		}
 	}
 
+	// Pin the collector to the variant's node subset
+	if len(pipeline.Spec.Nodes) > 0 {
+		ds.Spec.Template.Spec.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      corev1.LabelHostname,
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   pipeline.Spec.Nodes,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
 	return ds
 }
 
@@ -348,6 +370,12 @@ func (r *PipelineReconciler) daemonSetEqual(a, b *appsv1.DaemonSet) bool {
 		return false
 	}
 
+	// Compare node placement
+	if !equality.Semantic.DeepEqual(a.Spec.Template.Spec.NodeSelector, b.Spec.Template.Spec.NodeSelector) ||
+		!equality.Semantic.DeepEqual(a.Spec.Template.Spec.Affinity, b.Spec.Template.Spec.Affinity) {
+		return false
+	}
+
 	return true
 }
 
@@ -0,0 +1,188 @@
+package pipelines
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+)
+
+// Render renders the OTel collector configuration of a visual pipeline from
+// a catalog template. The processors of the pipeline run in connection order
+// after the template's metrics processors, before batching; an empty pipeline
+// renders the template unchanged. Experiment and variant stay placeholders
+// that the deployment fills in.
+func Render(name string, pipeline *pb.VisualPipeline) ([]byte, error) {
+	nodes, err := orderNodes(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := Template(name)
+	if err != nil {
+		return nil, err
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(template, &config); err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	if len(nodes) == 0 {
+		return yaml.Marshal(config)
+	}
+	processors, _ := config["processors"].(map[string]interface{})
+	service, _ := config["service"].(map[string]interface{})
+	servicePipelines, _ := service["pipelines"].(map[string]interface{})
+	metrics, _ := servicePipelines["metrics"].(map[string]interface{})
+	order, _ := metrics["processors"].([]interface{})
+	if processors == nil || order == nil {
+		return nil, fmt.Errorf("template %s has no metrics pipeline processors", name)
+	}
+
+	// Batching stays last so the variant's processors see every datapoint
+	batch := len(order)
+	for i, name := range order {
+		if name == "batch" {
+			batch = i
+		}
+	}
+	rendered := append([]interface{}{}, order[:batch]...)
+	for _, node := range nodes {
+		name, processor, err := renderProcessor(node)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			continue
+		}
+		processors[name] = processor
+		rendered = append(rendered, name)
+	}
+	metrics["processors"] = append(rendered, order[batch:]...)
+
+	return yaml.Marshal(config)
+}
+
+// renderProcessor maps a visual pipeline node to a collector processor named
+// after the node. The dashboard creates nodes without configuration, so a
+// node missing the keys its processor needs renders nothing and the pipeline
+// runs as if it were not there. Node types the deployed collector cannot
+// run are errors.
+func renderProcessor(node *pb.ProcessorNode) (string, interface{}, error) {
+	value := func(key string) string {
+		return strings.TrimSpace(node.Config[key])
+	}
+
+	switch node.Type {
+	case pb.ProcessorType_PROCESSOR_TYPE_FILTER:
+		// Datapoints matching the condition are dropped
+		condition := value("condition")
+		if condition == "" {
+			return "", nil, nil
+		}
+		return "filter/" + node.Id, map[string]interface{}{
+			"metrics": map[string]interface{}{"datapoint": []string{condition}},
+		}, nil
+	case pb.ProcessorType_PROCESSOR_TYPE_TRANSFORM:
+		// One OTTL statement per line
+		statements := splitNonEmpty(value("statements"), "\n")
+		if len(statements) == 0 {
+			return "", nil, nil
+		}
+		return "transform/" + node.Id, map[string]interface{}{
+			"metric_statements": []map[string]interface{}{
+				{"context": "datapoint", "statements": statements},
+			},
+		}, nil
+	case pb.ProcessorType_PROCESSOR_TYPE_AGGREGATE:
+		keys := splitNonEmpty(value("keys"), ",")
+		if len(keys) == 0 {
+			return "", nil, nil
+		}
+		return "groupbyattrs/" + node.Id, map[string]interface{}{"keys": keys}, nil
+	case pb.ProcessorType_PROCESSOR_TYPE_SAMPLE:
+		// Sampling metrics needs the interval processor, which the pinned
+		// collector (otel/opentelemetry-collector-contrib 0.88.0) predates
+		return "", nil, fmt.Errorf("processor %s: sample processors are not supported by the deployed collector", node.Id)
+	default:
+		return "", nil, fmt.Errorf("processor %s: unsupported type %s", node.Id, node.Type)
+	}
+}
+
+// Validate checks the graph of a visual pipeline: connections must reference
+// its nodes without forming a cycle, and every node must be of a type the
+// deployed collector supports. Node config is not required; nodes the
+// dashboard has not configured render nothing.
+func Validate(pipeline *pb.VisualPipeline) error {
+	nodes, err := orderNodes(pipeline)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if _, _, err := renderProcessor(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderNodes sorts the nodes of a visual pipeline so every node comes
+// after the nodes connected to it. Connections must not form a cycle;
+// otherwise nodes keep their relative order.
+func orderNodes(pipeline *pb.VisualPipeline) ([]*pb.ProcessorNode, error) {
+	byID := make(map[string]*pb.ProcessorNode, len(pipeline.GetNodes()))
+	for _, n := range pipeline.GetNodes() {
+		if n.Id == "" {
+			return nil, fmt.Errorf("processor id is required")
+		}
+		if _, ok := byID[n.Id]; ok {
+			return nil, fmt.Errorf("duplicate processor %s", n.Id)
+		}
+		byID[n.Id] = n
+	}
+
+	incoming := make(map[string]int, len(byID))
+	next := make(map[string][]string, len(byID))
+	for _, c := range pipeline.GetConnections() {
+		if byID[c.Source] == nil || byID[c.Target] == nil {
+			return nil, fmt.Errorf("connection %s -> %s references an unknown processor", c.Source, c.Target)
+		}
+		next[c.Source] = append(next[c.Source], c.Target)
+		incoming[c.Target]++
+	}
+
+	var ready []string
+	for _, n := range pipeline.GetNodes() {
+		if incoming[n.Id] == 0 {
+			ready = append(ready, n.Id)
+		}
+	}
+
+	ordered := make([]*pb.ProcessorNode, 0, len(byID))
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, byID[id])
+		for _, target := range next[id] {
+			incoming[target]--
+			if incoming[target] == 0 {
+				ready = append(ready, target)
+			}
+		}
+	}
+	if len(ordered) != len(byID) {
+		return nil, fmt.Errorf("pipeline connections form a cycle")
+	}
+	return ordered, nil
+}
+
+func splitNonEmpty(s, sep string) []string {
+	var parts []string
+	for _, p := range strings.Split(s, sep) {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,101 @@
+package pipelines
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+)
+
+func TestRenderOrdersProcessorsBeforeBatch(t *testing.T) {
+	pipeline := &pb.VisualPipeline{
+		Nodes: []*pb.ProcessorNode{
+			{Id: "group", Type: pb.ProcessorType_PROCESSOR_TYPE_AGGREGATE, Config: map[string]string{"keys": "process.executable.name, host.name"}},
+			{Id: "drop", Type: pb.ProcessorType_PROCESSOR_TYPE_FILTER, Config: map[string]string{"condition": `attributes["process.owner"] == "root"`}},
+			{Id: "unset", Type: pb.ProcessorType_PROCESSOR_TYPE_TRANSFORM},
+		},
+		// group is listed first but runs after drop
+		Connections: []*pb.Connection{{Source: "drop", Target: "group"}},
+	}
+
+	rendered, err := Render(Baseline, pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Processors map[string]interface{} `yaml:"processors"`
+		Service    struct {
+			Pipelines map[string]struct {
+				Processors []string `yaml:"processors"`
+			} `yaml:"pipelines"`
+		} `yaml:"service"`
+	}
+	if err := yaml.Unmarshal(rendered, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"memory_limiter", "cumulativetodelta", "resourcedetection/system", "resource/add_experiment_info", "filter/drop", "groupbyattrs/group", "batch"}
+	if got := config.Service.Pipelines["metrics"].Processors; !reflect.DeepEqual(got, want) {
+		t.Errorf("processors = %v, want %v", got, want)
+	}
+	if _, ok := config.Processors["transform/unset"]; ok {
+		t.Error("unconfigured node was rendered")
+	}
+	if !strings.Contains(string(rendered), "${PHOENIX_VARIANT}") {
+		t.Error("variant placeholder was not kept for the deployment")
+	}
+}
+
+func TestRenderEmptyPipelineIsTemplate(t *testing.T) {
+	template, err := Template(Baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := Render(Baseline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, got interface{}
+	if err := yaml.Unmarshal(template, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(rendered, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("empty pipeline changed the template")
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	filter := func(id string) *pb.ProcessorNode {
+		return &pb.ProcessorNode{Id: id, Type: pb.ProcessorType_PROCESSOR_TYPE_FILTER}
+	}
+	tests := []struct {
+		name     string
+		pipeline *pb.VisualPipeline
+		want     string
+	}{
+		{"sample", &pb.VisualPipeline{Nodes: []*pb.ProcessorNode{{Id: "s", Type: pb.ProcessorType_PROCESSOR_TYPE_SAMPLE}}}, "not supported"},
+		{"duplicate", &pb.VisualPipeline{Nodes: []*pb.ProcessorNode{filter("a"), filter("a")}}, "duplicate"},
+		{"unknown connection", &pb.VisualPipeline{Nodes: []*pb.ProcessorNode{filter("a")}, Connections: []*pb.Connection{{Source: "a", Target: "b"}}}, "unknown processor"},
+		{"cycle", &pb.VisualPipeline{
+			Nodes:       []*pb.ProcessorNode{filter("a"), filter("b")},
+			Connections: []*pb.Connection{{Source: "a", Target: "b"}, {Source: "b", Target: "a"}},
+		}, "cycle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.pipeline); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+			if _, err := Render(Baseline, tt.pipeline); err == nil {
+				t.Error("Render accepted an invalid pipeline")
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+// Package pipelines holds the catalog of OTel collector pipeline templates.
+// Templates leave experiment-specific values as ${PHOENIX_EXPERIMENT_ID},
+// ${PHOENIX_VARIANT} and ${NODE_NAME} placeholders, which the collector
+// expands from the environment its deployment gives it.
+package pipelines
+
+import (
+	"embed"
+	"fmt"
+)
+
+// Baseline is the template every experiment variant is rendered from
+const Baseline = "process-baseline-v1"
+
+//go:embed templates/*.yaml
+var templates embed.FS
+
+// Template returns the collector configuration of a catalog template
+func Template(name string) ([]byte, error) {
+	content, err := templates.ReadFile("templates/" + name + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown pipeline template %q", name)
+	}
+	return content, nil
+}
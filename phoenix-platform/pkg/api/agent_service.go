@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// Agent states reported by ListAgents
+const (
+	AgentStatusHealthy = "healthy"
+	AgentStatusStale   = "stale"
+)
+
+// AgentService records collector heartbeats and lists the fleet
+type AgentService struct {
+	pb.UnimplementedAgentServiceServer
+	store      store.AgentStore
+	staleAfter time.Duration
+	logger     *zap.Logger
+}
+
+// NewAgentService creates the service. Agents without a heartbeat for
+// staleAfter are reported as stale.
+func NewAgentService(store store.AgentStore, staleAfter time.Duration, logger *zap.Logger) *AgentService {
+	return &AgentService{
+		store:      store,
+		staleAfter: staleAfter,
+		logger:     logger,
+	}
+}
+
+func (s *AgentService) ReportAgentStatus(ctx context.Context, req *pb.ReportAgentStatusRequest) (*pb.Agent, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.Hostname == "" {
+		return nil, status.Error(codes.InvalidArgument, "hostname is required")
+	}
+
+	agent := &store.Agent{
+		ID:                  req.AgentId,
+		Hostname:            req.Hostname,
+		Version:             req.Version,
+		PolicyHash:          req.PolicyHash,
+		Mode:                req.Mode,
+		CardinalityEstimate: req.CardinalityEstimate,
+		Labels:              req.Labels,
+	}
+	if err := s.store.UpsertAgent(ctx, agent); err != nil {
+		s.logger.Error("failed to record agent heartbeat",
+			zap.String("agent_id", req.AgentId),
+			zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to record heartbeat: %v", err)
+	}
+
+	return s.agentToProto(agent, time.Now()), nil
+}
+
+func (s *AgentService) ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error) {
+	now := time.Now()
+	filter := store.AgentFilter{
+		Version:    req.Version,
+		PolicyHash: req.PolicyHash,
+		Mode:       req.Mode,
+		Limit:      int(req.Limit),
+		Offset:     int(req.Offset),
+	}
+
+	switch req.Status {
+	case "":
+	case AgentStatusHealthy:
+		filter.SeenAfter = now.Add(-s.staleAfter)
+	case AgentStatusStale:
+		filter.SeenBefore = now.Add(-s.staleAfter)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid status: %s", req.Status)
+	}
+
+	agents, total, err := s.store.ListAgents(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list agents: %v", err)
+	}
+
+	resp := &pb.ListAgentsResponse{
+		Agents:     make([]*pb.Agent, len(agents)),
+		Total:      int32(total),
+		StaleAfter: durationpb.New(s.staleAfter),
+	}
+	for i, a := range agents {
+		resp.Agents[i] = s.agentToProto(a, now)
+	}
+	return resp, nil
+}
+
+func (s *AgentService) agentToProto(a *store.Agent, now time.Time) *pb.Agent {
+	state := AgentStatusHealthy
+	if now.Sub(a.LastSeen) > s.staleAfter {
+		state = AgentStatusStale
+	}
+
+	return &pb.Agent{
+		Id:                  a.ID,
+		Hostname:            a.Hostname,
+		Version:             a.Version,
+		PolicyHash:          a.PolicyHash,
+		Mode:                a.Mode,
+		CardinalityEstimate: a.CardinalityEstimate,
+		Labels:              a.Labels,
+		FirstSeen:           timestamppb.New(a.FirstSeen),
+		LastSeen:            timestamppb.New(a.LastSeen),
+		Status:              state,
+	}
+}
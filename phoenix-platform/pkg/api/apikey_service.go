@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/apikeys"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// APIKeyService lets admins create, rotate and revoke API keys
+type APIKeyService struct {
+	pb.UnimplementedAPIKeyServiceServer
+	store  store.APIKeyStore
+	logger *zap.Logger
+}
+
+func NewAPIKeyService(store store.APIKeyStore, logger *zap.Logger) *APIKeyService {
+	return &APIKeyService{
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	if !hasAdminRole(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can manage API keys")
+	}
+	if req.Tenant == "" {
+		return nil, status.Error(codes.InvalidArgument, "tenant is required")
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !apikeys.ValidScope(scope) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown scope %q, expected read, write or admin", scope)
+		}
+	}
+
+	var expiresAt time.Time
+	if req.Lifetime != nil {
+		lifetime := req.Lifetime.AsDuration()
+		if lifetime <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "lifetime must be positive")
+		}
+		expiresAt = time.Now().Add(lifetime)
+	}
+
+	user, _ := ctx.Value("user").(string)
+	return s.issue(ctx, &store.APIKey{
+		Tenant:    req.Tenant,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		CreatedBy: user,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	if !hasAdminRole(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can manage API keys")
+	}
+
+	keys, err := s.store.ListAPIKeys(ctx, req.Tenant)
+	if err != nil {
+		s.logger.Error("failed to list API keys", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list API keys")
+	}
+
+	resp := &pb.ListAPIKeysResponse{Keys: make([]*pb.APIKey, len(keys))}
+	for i, key := range keys {
+		resp.Keys[i] = apiKeyToProto(key)
+	}
+	return resp, nil
+}
+
+// RotateAPIKey issues a replacement with the same tenant, name, scopes and
+// lifetime, then revokes the old key
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, req *pb.RotateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	if !hasAdminRole(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can manage API keys")
+	}
+
+	old, err := s.store.GetAPIKey(ctx, req.KeyId)
+	if err == store.ErrAPIKeyNotFound {
+		return nil, status.Error(codes.NotFound, "API key not found")
+	}
+	if err != nil {
+		s.logger.Error("failed to get API key", zap.String("key_id", req.KeyId), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to get API key")
+	}
+	if !old.RevokedAt.IsZero() {
+		return nil, status.Error(codes.FailedPrecondition, "API key is already revoked")
+	}
+
+	user, _ := ctx.Value("user").(string)
+	replacement := &store.APIKey{
+		Tenant:    old.Tenant,
+		Name:      old.Name,
+		Scopes:    old.Scopes,
+		CreatedBy: user,
+	}
+	if !old.ExpiresAt.IsZero() {
+		replacement.ExpiresAt = time.Now().Add(old.ExpiresAt.Sub(old.CreatedAt))
+	}
+
+	resp, err := s.issue(ctx, replacement)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.RevokeAPIKey(ctx, old.ID); err != nil {
+		// Don't leave two live keys behind
+		if revokeErr := s.store.RevokeAPIKey(ctx, replacement.ID); revokeErr != nil {
+			s.logger.Error("failed to revoke replacement API key", zap.String("key_id", replacement.ID), zap.Error(revokeErr))
+		}
+		s.logger.Error("failed to revoke rotated API key", zap.String("key_id", old.ID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to rotate API key")
+	}
+
+	s.logger.Info("API key rotated",
+		zap.String("key_id", old.ID),
+		zap.String("replacement_id", replacement.ID),
+		zap.String("user", user))
+	return resp, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	if !hasAdminRole(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can manage API keys")
+	}
+
+	err := s.store.RevokeAPIKey(ctx, req.KeyId)
+	if err == store.ErrAPIKeyNotFound {
+		return nil, status.Error(codes.NotFound, "API key not found or already revoked")
+	}
+	if err != nil {
+		s.logger.Error("failed to revoke API key", zap.String("key_id", req.KeyId), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke API key")
+	}
+
+	user, _ := ctx.Value("user").(string)
+	s.logger.Info("API key revoked", zap.String("key_id", req.KeyId), zap.String("user", user))
+	return &pb.RevokeAPIKeyResponse{}, nil
+}
+
+// issue generates a secret for key, stores its hash and returns the secret
+func (s *APIKeyService) issue(ctx context.Context, key *store.APIKey) (*pb.CreateAPIKeyResponse, error) {
+	id, err := apikeys.NewID()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate API key")
+	}
+	token, prefix, hash, err := apikeys.Generate()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate API key")
+	}
+	key.ID = id
+	key.Prefix = prefix
+	key.Hash = hash
+
+	if err := s.store.CreateAPIKey(ctx, key); err != nil {
+		s.logger.Error("failed to store API key", zap.String("tenant", key.Tenant), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to create API key")
+	}
+
+	s.logger.Info("API key created",
+		zap.String("key_id", key.ID),
+		zap.String("tenant", key.Tenant),
+		zap.Strings("scopes", key.Scopes),
+		zap.String("user", key.CreatedBy))
+	return &pb.CreateAPIKeyResponse{Key: apiKeyToProto(key), Secret: token}, nil
+}
+
+func apiKeyToProto(key *store.APIKey) *pb.APIKey {
+	pbKey := &pb.APIKey{
+		Id:        key.ID,
+		Tenant:    key.Tenant,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedBy: key.CreatedBy,
+		CreatedAt: timestamppb.New(key.CreatedAt),
+	}
+	if !key.ExpiresAt.IsZero() {
+		pbKey.ExpiresAt = timestamppb.New(key.ExpiresAt)
+	}
+	if !key.LastUsedAt.IsZero() {
+		pbKey.LastUsedAt = timestamppb.New(key.LastUsedAt)
+	}
+	if !key.RevokedAt.IsZero() {
+		pbKey.RevokedAt = timestamppb.New(key.RevokedAt)
+	}
+	return pbKey
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/phoenix/platform/pipelines"
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/models"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+func (s *ExperimentService) GetExperimentArtifacts(ctx context.Context, req *pb.GetExperimentArtifactsRequest) (*pb.GetExperimentArtifactsResponse, error) {
+	if s.artifacts == nil {
+		return nil, status.Error(codes.Unimplemented, "artifact storage is not configured")
+	}
+
+	exp, err := s.store.GetExperiment(ctx, req.ExperimentId)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "experiment not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get experiment: %v", err)
+	}
+
+	// Check permissions
+	user, _ := ctx.Value("user").(string)
+	if exp.Owner != user && !s.isAdmin(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "access denied")
+	}
+
+	artifacts, err := s.artifacts.ListArtifacts(ctx, req.ExperimentId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list artifacts: %v", err)
+	}
+
+	resp := &pb.GetExperimentArtifactsResponse{}
+	for _, a := range artifacts {
+		if req.Variant != "" && a.Variant != req.Variant {
+			continue
+		}
+		resp.Artifacts = append(resp.Artifacts, &pb.Artifact{
+			Variant:     a.Variant,
+			Kind:        a.Kind,
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Content:     a.Content,
+			Sha256:      a.SHA256,
+			CreatedAt:   timestamppb.New(a.CreatedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+// saveArtifacts persists everything needed to reproduce the deployment of
+// each variant: its template parameters, the PhoenixProcessPipeline manifest
+// and the rendered collector config.
+func (s *ExperimentService) saveArtifacts(ctx context.Context, exp *models.Experiment) {
+	if s.artifacts == nil {
+		return
+	}
+
+	artifacts, err := s.renderArtifacts(ctx, exp)
+	if err != nil {
+		s.logger.Error("failed to render experiment artifacts",
+			zap.String("experiment_id", exp.ID),
+			zap.Error(err))
+		return
+	}
+
+	if err := s.artifacts.SaveArtifacts(ctx, artifacts); err != nil {
+		s.logger.Error("failed to save experiment artifacts",
+			zap.String("experiment_id", exp.ID),
+			zap.Error(err))
+	}
+}
+
+func (s *ExperimentService) renderArtifacts(ctx context.Context, exp *models.Experiment) ([]*store.Artifact, error) {
+	nodes, err := assignVariantNodes(exp.Spec)
+	if err != nil {
+		return nil, err
+	}
+	var selector map[string]string
+	if exp.Spec.Target != nil {
+		selector = exp.Spec.Target.NodeLabels
+	}
+
+	var artifacts []*store.Artifact
+	for _, v := range exp.Spec.Variants {
+		params, err := protojson.MarshalOptions{Indent: "  "}.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: failed to encode parameters: %w", v.Name, err)
+		}
+		artifacts = append(artifacts, &store.Artifact{
+			ExperimentID: exp.ID,
+			Variant:      v.Name,
+			Kind:         store.ArtifactKindParameters,
+			Name:         fmt.Sprintf("%s-parameters.json", v.Name),
+			ContentType:  "application/json",
+			Content:      params,
+		})
+
+		manifest, err := renderPipelineManifest(exp, v.Name, nodes[v.Name], selector)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: failed to render manifest: %w", v.Name, err)
+		}
+		artifacts = append(artifacts, &store.Artifact{
+			ExperimentID: exp.ID,
+			Variant:      v.Name,
+			Kind:         store.ArtifactKindManifest,
+			Name:         fmt.Sprintf("%s-pipeline.yaml", v.Name),
+			ContentType:  "application/yaml",
+			Content:      manifest,
+		})
+
+		config, err := pipelines.Render(pipelines.Baseline, v.GetPipeline())
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: failed to render collector config: %w", v.Name, err)
+		}
+		artifacts = append(artifacts, &store.Artifact{
+			ExperimentID: exp.ID,
+			Variant:      v.Name,
+			Kind:         store.ArtifactKindCollectorConfig,
+			Name:         fmt.Sprintf("%s-collector.yaml", v.Name),
+			ContentType:  "application/yaml",
+			Content:      config,
+		})
+	}
+
+	return artifacts, nil
+}
+
+// renderPipelineManifest renders the PhoenixProcessPipeline custom resource
+// the pipeline operator reconciles for a variant. It is stored and applied
+// as is, so it carries the nodes the variant is restricted to.
+func renderPipelineManifest(exp *models.Experiment, variant string, nodes []string, selector map[string]string) ([]byte, error) {
+	name := fmt.Sprintf("%s-%s", exp.ID, variant)
+	spec := map[string]interface{}{
+		"experimentID": exp.ID,
+		"variant":      variant,
+		"configMap":    fmt.Sprintf("%s-config", name),
+	}
+	if len(nodes) > 0 {
+		spec["nodes"] = nodes
+	}
+	if len(selector) > 0 {
+		spec["nodeSelector"] = selector
+	}
+	manifest := map[string]interface{}{
+		"apiVersion": "phoenix.io/v1alpha1",
+		"kind":       "PhoenixProcessPipeline",
+		"metadata": map[string]interface{}{
+			"name": name,
+			"labels": map[string]string{
+				"phoenix.io/experiment-id": exp.ID,
+				"phoenix.io/variant":       variant,
+			},
+		},
+		"spec": spec,
+	}
+	return yaml.Marshal(manifest)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// AuditService lets admins query the audit log
+type AuditService struct {
+	pb.UnimplementedAuditServiceServer
+	store  store.AuditStore
+	logger *zap.Logger
+}
+
+func NewAuditService(store store.AuditStore, logger *zap.Logger) *AuditService {
+	return &AuditService{
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (s *AuditService) ListAuditEntries(ctx context.Context, req *pb.ListAuditEntriesRequest) (*pb.ListAuditEntriesResponse, error) {
+	if !hasAdminRole(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can read the audit log")
+	}
+
+	filter := store.AuditFilter{
+		Actor:  req.Actor,
+		Tenant: req.Tenant,
+		Method: req.Method,
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	}
+	if req.Since != nil {
+		filter.Since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		filter.Until = req.Until.AsTime()
+	}
+
+	entries, total, err := s.store.ListAuditEntries(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to list audit entries", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list audit entries")
+	}
+
+	resp := &pb.ListAuditEntriesResponse{
+		Entries: make([]*pb.AuditEntry, len(entries)),
+		Total:   int32(total),
+	}
+	for i, e := range entries {
+		resp.Entries[i] = &pb.AuditEntry{
+			Id:         e.ID,
+			Time:       timestamppb.New(e.Time),
+			Actor:      e.Actor,
+			ActorType:  e.ActorType,
+			Tenant:     e.Tenant,
+			Method:     e.Method,
+			Resource:   e.Resource,
+			RemoteAddr: e.RemoteAddr,
+			Code:       e.Code,
+			Error:      e.Error,
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/models"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+func (s *ExperimentService) CompareExperiments(ctx context.Context, req *pb.CompareExperimentsRequest) (*pb.CompareExperimentsResponse, error) {
+	exp, err := s.getAccessibleExperiment(ctx, req.ExperimentId)
+	if err != nil {
+		return nil, err
+	}
+	other, err := s.getAccessibleExperiment(ctx, req.OtherExperimentId)
+	if err != nil {
+		return nil, err
+	}
+
+	differences, err := diffSpecs(exp.Spec, other.Spec)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to compare specs: %v", err)
+	}
+
+	return &pb.CompareExperimentsResponse{
+		Experiment:      s.modelToProto(exp),
+		OtherExperiment: s.modelToProto(other),
+		Differences:     differences,
+		Kpis:            compareKPIs(exp.Status.Metrics, other.Status.Metrics),
+	}, nil
+}
+
+func (s *ExperimentService) getAccessibleExperiment(ctx context.Context, id string) (*models.Experiment, error) {
+	exp, err := s.store.GetExperiment(ctx, id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "experiment %s not found", id)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get experiment: %v", err)
+	}
+
+	// Check permissions
+	user, _ := ctx.Value("user").(string)
+	if exp.Owner != user && !s.isAdmin(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "access denied")
+	}
+
+	return exp, nil
+}
+
+// diffSpecs flattens both specs into dotted paths and reports every path
+// whose value differs. Variants are keyed by name and processor nodes by id
+// so that reordering them is not reported as a change.
+func diffSpecs(spec, other *pb.ExperimentSpec) ([]*pb.ConfigDifference, error) {
+	a, err := flattenSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	b, err := flattenSpec(other)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]struct{}, len(a)+len(b))
+	for p := range a {
+		paths[p] = struct{}{}
+	}
+	for p := range b {
+		paths[p] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	var diffs []*pb.ConfigDifference
+	for _, p := range sorted {
+		va, inA := a[p]
+		vb, inB := b[p]
+		switch {
+		case inA && !inB:
+			diffs = append(diffs, &pb.ConfigDifference{Path: p, Change: "removed", Value: va})
+		case !inA && inB:
+			diffs = append(diffs, &pb.ConfigDifference{Path: p, Change: "added", OtherValue: vb})
+		case va != vb:
+			diffs = append(diffs, &pb.ConfigDifference{Path: p, Change: "changed", Value: va, OtherValue: vb})
+		}
+	}
+	return diffs, nil
+}
+
+func flattenSpec(spec *pb.ExperimentSpec) (map[string]string, error) {
+	out := make(map[string]string)
+	if spec == nil {
+		return out, nil
+	}
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if variants, ok := doc["variants"].([]interface{}); ok {
+		byName := keyBy(variants, "name")
+		for _, v := range byName {
+			variant, _ := v.(map[string]interface{})
+			pipeline, _ := variant["pipeline"].(map[string]interface{})
+			if nodes, ok := pipeline["nodes"].([]interface{}); ok {
+				pipeline["nodes"] = keyBy(nodes, "id")
+			}
+		}
+		doc["variants"] = byName
+	}
+
+	flatten("", doc, out)
+	return out, nil
+}
+
+// keyBy turns a list of objects into an object keyed by the given field,
+// falling back to the list index when the field is missing
+func keyBy(items []interface{}, field string) map[string]interface{} {
+	out := make(map[string]interface{}, len(items))
+	for i, item := range items {
+		key := fmt.Sprintf("%d", i)
+		if m, ok := item.(map[string]interface{}); ok {
+			if k, ok := m[field].(string); ok && k != "" {
+				key = k
+				delete(m, field)
+			}
+		}
+		out[key] = item
+	}
+	return out
+}
+
+func flatten(prefix string, value interface{}, out map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			flatten(path, child, out)
+		}
+	case []interface{}:
+		// Lists of scalars (target nodes, critical processes, connections)
+		// are compared as a whole
+		data, _ := json.Marshal(v)
+		out[prefix] = string(data)
+	case string:
+		out[prefix] = v
+	default:
+		data, _ := json.Marshal(v)
+		out[prefix] = strings.TrimSpace(string(data))
+	}
+}
+
+func compareKPIs(m, other *pb.MetricsSummary) []*pb.KPIComparison {
+	type kpi struct {
+		name  string
+		unit  string
+		value func(*pb.MetricsSummary) float64
+	}
+	kpis := []kpi{
+		{"cardinality_reduction", "percent", func(m *pb.MetricsSummary) float64 { return m.CardinalityReductionPercent }},
+		{"variant_cardinality", "series", func(m *pb.MetricsSummary) float64 { return float64(m.VariantCardinality) }},
+		{"cost_reduction", "percent", func(m *pb.MetricsSummary) float64 { return m.CostReductionPercent }},
+		{"collector_cpu", "percent", func(m *pb.MetricsSummary) float64 { return m.CollectorCpuPercent }},
+		{"critical_process_preservation", "percent", criticalProcessPreservation},
+	}
+
+	out := make([]*pb.KPIComparison, 0, len(kpis))
+	for _, k := range kpis {
+		c := &pb.KPIComparison{
+			Name:      k.name,
+			Unit:      k.unit,
+			Available: m != nil && other != nil,
+		}
+		if m != nil {
+			c.Value = k.value(m)
+		}
+		if other != nil {
+			c.OtherValue = k.value(other)
+		}
+		if c.Available {
+			c.Delta = c.OtherValue - c.Value
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func criticalProcessPreservation(m *pb.MetricsSummary) float64 {
+	if len(m.CriticalProcessCoverage) == 0 {
+		return 100
+	}
+
+	covered := 0
+	for _, p := range m.CriticalProcessCoverage {
+		if p.Covered {
+			covered++
+		}
+	}
+	return float64(covered) / float64(len(m.CriticalProcessCoverage)) * 100
+}
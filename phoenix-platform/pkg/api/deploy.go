@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/phoenix/platform/pkg/deploy"
+	"github.com/phoenix/platform/pkg/models"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// backendFor returns the backend deploying to an environment. Kubernetes
+// variants are left to GitOps, as before backends existed, when no Kubernetes
+// backend is configured; the backend is nil then.
+func (s *ExperimentService) backendFor(environment string) (deploy.Backend, error) {
+	backend, err := s.deployers.For(environment)
+	if err != nil {
+		if environment == "" || environment == deploy.EnvironmentKubernetes {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return backend, nil
+}
+
+// deployVariants rolls every variant out through the backend for the
+// experiment's target environment
+func (s *ExperimentService) deployVariants(ctx context.Context, exp *models.Experiment) error {
+	backend, err := s.backendFor(exp.Spec.TargetEnvironment)
+	if err != nil || backend == nil {
+		return err
+	}
+
+	deployments, err := s.deployments(ctx, exp)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deployments {
+		if err := backend.Deploy(ctx, d); err != nil {
+			return fmt.Errorf("variant %s: %w", d.Variant, err)
+		}
+		s.logger.Info("deployed variant",
+			zap.String("experiment_id", exp.ID),
+			zap.String("variant", d.Variant),
+			zap.String("backend", backend.Name()))
+	}
+	return nil
+}
+
+// removeVariants tears down the named variants, or all of them when none are
+// named
+func (s *ExperimentService) removeVariants(exp *models.Experiment, variants ...string) {
+	backend, err := s.backendFor(exp.Spec.TargetEnvironment)
+	if err != nil {
+		s.logger.Warn("cannot remove variants", zap.String("experiment_id", exp.ID), zap.Error(err))
+		return
+	}
+	if backend == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	deployments, err := s.deployments(ctx, exp)
+	if err != nil {
+		s.logger.Warn("cannot remove variants", zap.String("experiment_id", exp.ID), zap.Error(err))
+		return
+	}
+
+	remove := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		remove[v] = true
+	}
+
+	for _, d := range deployments {
+		if len(remove) > 0 && !remove[d.Variant] {
+			continue
+		}
+		if err := backend.Remove(ctx, d); err != nil {
+			s.logger.Warn("failed to remove variant",
+				zap.String("experiment_id", exp.ID),
+				zap.String("variant", d.Variant),
+				zap.Error(err))
+		}
+	}
+}
+
+// deployments builds one deployment per variant from the rendered artifacts
+func (s *ExperimentService) deployments(ctx context.Context, exp *models.Experiment) ([]*deploy.Deployment, error) {
+	artifacts, err := s.renderArtifacts(ctx, exp)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := assignVariantNodes(exp.Spec)
+	if err != nil {
+		return nil, err
+	}
+	var selector map[string]string
+	if exp.Spec.Target != nil {
+		selector = exp.Spec.Target.NodeLabels
+	}
+
+	byVariant := make(map[string]*deploy.Deployment)
+	var deployments []*deploy.Deployment
+	for _, a := range artifacts {
+		d, ok := byVariant[a.Variant]
+		if !ok {
+			d = &deploy.Deployment{
+				ExperimentID: exp.ID,
+				Variant:      a.Variant,
+				Nodes:        nodes[a.Variant],
+				NodeSelector: selector,
+			}
+			byVariant[a.Variant] = d
+			deployments = append(deployments, d)
+		}
+
+		switch a.Kind {
+		case store.ArtifactKindCollectorConfig:
+			d.CollectorConfig = a.Content
+		case store.ArtifactKindManifest:
+			d.Manifest = a.Content
+		}
+	}
+	return deployments, nil
+}
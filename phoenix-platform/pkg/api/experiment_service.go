@@ -8,28 +8,110 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/deploy"
+	"github.com/phoenix/platform/pkg/eventbus"
+	"github.com/phoenix/platform/pkg/exporter"
 	"github.com/phoenix/platform/pkg/generator"
+	"github.com/phoenix/platform/pkg/grafana"
+	"github.com/phoenix/platform/pkg/guardrails"
 	"github.com/phoenix/platform/pkg/models"
+	"github.com/phoenix/platform/pkg/notifications"
 	"github.com/phoenix/platform/pkg/store"
 	"github.com/phoenix/platform/pkg/utils"
 )
 
 type ExperimentService struct {
 	pb.UnimplementedExperimentServiceServer
-	store     store.ExperimentStore
-	generator generator.Service
-	logger    *zap.Logger
+	store        store.ExperimentStore
+	generator    generator.Service
+	exporter     exporter.Exporter
+	artifacts    store.ArtifactStore
+	specVersions store.SpecVersionStore
+	phases       store.PhaseStore
+	dashboards   *grafana.Provisioner
+	events       eventbus.Bus
+	deployers    deploy.Backends
+	notifier     *notifications.Notifier
+	guardrails   *guardrails.Monitor
+	logger       *zap.Logger
 }
 
-func NewExperimentService(store store.ExperimentStore, generator generator.Service, logger *zap.Logger) *ExperimentService {
-	return &ExperimentService{
+// Option configures optional integrations of the ExperimentService
+type Option func(*ExperimentService)
+
+// WithResultExporter pushes experiment verdicts to external observability backends
+func WithResultExporter(e exporter.Exporter) Option {
+	return func(s *ExperimentService) {
+		s.exporter = e
+	}
+}
+
+// WithArtifactStore persists rendered configs and manifests for each experiment
+func WithArtifactStore(a store.ArtifactStore) Option {
+	return func(s *ExperimentService) {
+		s.artifacts = a
+	}
+}
+
+// WithSpecVersionStore keeps every revision of each experiment's spec
+func WithSpecVersionStore(v store.SpecVersionStore) Option {
+	return func(s *ExperimentService) {
+		s.specVersions = v
+	}
+}
+
+// WithPhaseStore makes reviews of proposed experiments atomic, so that two
+// reviewers cannot both decide on the same draft
+func WithPhaseStore(p store.PhaseStore) Option {
+	return func(s *ExperimentService) {
+		s.phases = p
+	}
+}
+
+// WithDashboardProvisioner creates a Grafana comparison dashboard for every
+// deployed experiment
+func WithDashboardProvisioner(p *grafana.Provisioner) Option {
+	return func(s *ExperimentService) {
+		s.dashboards = p
+	}
+}
+
+// WithEventBus publishes experiment phase transitions for other services
+func WithEventBus(bus eventbus.Bus) Option {
+	return func(s *ExperimentService) {
+		s.events = bus
+	}
+}
+
+// WithDeployBackends rolls variants out through the backend matching each
+// experiment's target environment
+func WithDeployBackends(backends deploy.Backends) Option {
+	return func(s *ExperimentService) {
+		s.deployers = backends
+	}
+}
+
+// WithNotifier announces experiment verdicts on chat and webhook channels
+func WithNotifier(n *notifications.Notifier) Option {
+	return func(s *ExperimentService) {
+		s.notifier = n
+	}
+}
+
+func NewExperimentService(store store.ExperimentStore, generator generator.Service, logger *zap.Logger, opts ...Option) *ExperimentService {
+	s := &ExperimentService{
 		store:     store,
 		generator: generator,
 		logger:    logger,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *ExperimentService) CreateExperiment(ctx context.Context, req *pb.CreateExperimentRequest) (*pb.CreateExperimentResponse, error) {
@@ -67,6 +149,11 @@ func (s *ExperimentService) CreateExperiment(ctx context.Context, req *pb.Create
 		return nil, status.Errorf(codes.Internal, "failed to create experiment: %v", err)
 	}
 
+	if err := s.recordSpecVersion(ctx, exp); err != nil {
+		s.logger.Error("experiment created without its spec version", zap.String("experiment_id", exp.ID), zap.Error(err))
+	}
+	s.publishStateChange(ctx, exp, pb.ExperimentStatus_PHASE_UNSPECIFIED)
+
 	// Trigger async generation
 	go s.generateArtifacts(exp)
 
@@ -150,6 +237,7 @@ func (s *ExperimentService) UpdateExperiment(ctx context.Context, req *pb.Update
 	}
 
 	// Update fields
+	previous, previousUpdatedAt := exp.Spec, exp.UpdatedAt
 	if req.Spec != nil {
 		if err := s.validateExperimentSpec(req.Spec); err != nil {
 			return nil, status.Errorf(codes.InvalidArgument, "invalid spec: %v", err)
@@ -163,6 +251,20 @@ func (s *ExperimentService) UpdateExperiment(ctx context.Context, req *pb.Update
 		return nil, status.Errorf(codes.Internal, "failed to update experiment: %v", err)
 	}
 
+	// A spec without its version would be missing from the history, so the
+	// update is undone when the version cannot be recorded
+	if req.Spec != nil {
+		if err := s.recordSpecVersion(ctx, exp); err != nil {
+			exp.Spec, exp.UpdatedAt = previous, previousUpdatedAt
+			if rerr := s.store.UpdateExperiment(ctx, exp); rerr != nil {
+				s.logger.Error("failed to restore experiment spec",
+					zap.String("experiment_id", exp.ID),
+					zap.Error(rerr))
+			}
+			return nil, status.Errorf(codes.Internal, "failed to update experiment: %v", err)
+		}
+	}
+
 	return s.modelToProto(exp), nil
 }
 
@@ -242,6 +344,17 @@ func (s *ExperimentService) StreamExperimentUpdates(req *pb.StreamExperimentUpda
 		zap.String("experiment_id", req.ExperimentId),
 		zap.String("user", user))
 
+	// The current state goes first so clients know the stream is live and
+	// need not fetch the experiment separately
+	if err := stream.Send(&pb.ExperimentUpdate{
+		ExperimentId: req.ExperimentId,
+		Status:       exp.Status.GetPhase().String(),
+		Metrics:      make(map[string]*pb.MetricValue),
+		Timestamp:    timestamppb.Now(),
+	}); err != nil {
+		return err
+	}
+
 	// Stream updates
 	for {
 		select {
@@ -309,6 +422,17 @@ func (s *ExperimentService) PromoteVariant(ctx context.Context, req *pb.PromoteV
 	if !validVariant {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid variant: %s", req.Variant)
 	}
+	if exp.Status.PromotedVariant != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "variant %s is already promoted", exp.Status.PromotedVariant)
+	}
+
+	// Recorded so platform status can count the savings from here on
+	exp.Status.PromotedVariant = req.Variant
+	exp.Status.PromotedAt = timestamppb.Now()
+	exp.UpdatedAt = time.Now()
+	if err := s.store.UpdateExperiment(ctx, exp); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update experiment: %v", err)
+	}
 
 	// TODO: Implement promotion logic
 	// This would typically:
@@ -321,6 +445,12 @@ func (s *ExperimentService) PromoteVariant(ctx context.Context, req *pb.PromoteV
 		zap.String("variant", req.Variant),
 		zap.String("user", user))
 
+	// The goroutines outlive the request, so they get their own copy
+	promoted := snapshot(exp)
+	go s.exportResult(promoted, exporter.VerdictPromoted, req.Variant)
+	go s.notifyResult(promoted, exporter.VerdictPromoted, req.Variant)
+	go s.retireDashboard(promoted)
+
 	return &pb.PromoteVariantResponse{
 		Success: true,
 		Message: fmt.Sprintf("Variant %s promoted successfully", req.Variant),
@@ -329,41 +459,6 @@ func (s *ExperimentService) PromoteVariant(ctx context.Context, req *pb.PromoteV
 
 // Helper methods
 
-func (s *ExperimentService) validateExperimentSpec(spec *pb.ExperimentSpec) error {
-	if spec == nil {
-		return fmt.Errorf("spec is required")
-	}
-
-	if spec.Name == "" {
-		return fmt.Errorf("name is required")
-	}
-
-	if len(spec.Variants) != 2 {
-		return fmt.Errorf("exactly 2 variants required (baseline and candidate)")
-	}
-
-	// Validate variants
-	hasBaseline := false
-	hasCandidate := false
-	for _, v := range spec.Variants {
-		if v.Name == "baseline" {
-			hasBaseline = true
-		} else if v.Name == "candidate" {
-			hasCandidate = true
-		}
-
-		if len(v.Pipeline.Nodes) == 0 {
-			return fmt.Errorf("variant %s must have at least one processor node", v.Name)
-		}
-	}
-
-	if !hasBaseline || !hasCandidate {
-		return fmt.Errorf("must have both baseline and candidate variants")
-	}
-
-	return nil
-}
-
 func (s *ExperimentService) generateArtifacts(exp *models.Experiment) {
 	ctx := context.Background()
 	
@@ -371,6 +466,7 @@ func (s *ExperimentService) generateArtifacts(exp *models.Experiment) {
 	exp.Status.Phase = pb.ExperimentStatus_PHASE_GENERATING
 	exp.Status.Message = "Generating pipeline configurations"
 	s.store.UpdateExperiment(ctx, exp)
+	s.publishStateChange(ctx, exp, pb.ExperimentStatus_PHASE_PENDING)
 
 	// Generate artifacts
 	if err := s.generator.GenerateArtifacts(ctx, exp); err != nil {
@@ -381,13 +477,35 @@ func (s *ExperimentService) generateArtifacts(exp *models.Experiment) {
 		exp.Status.Phase = pb.ExperimentStatus_PHASE_FAILED
 		exp.Status.Message = fmt.Sprintf("Generation failed: %v", err)
 		s.store.UpdateExperiment(ctx, exp)
+		s.publishStateChange(ctx, exp, pb.ExperimentStatus_PHASE_GENERATING)
+		s.exportResult(exp, exporter.VerdictFailed, "")
+		s.notifyResult(exp, exporter.VerdictFailed, "")
 		return
 	}
 
+	// Keep what was rendered so the experiment can be reproduced later
+	s.saveArtifacts(ctx, exp)
+
 	// Update status
 	exp.Status.Phase = pb.ExperimentStatus_PHASE_DEPLOYING
 	exp.Status.Message = "Deploying pipelines"
+	s.provisionDashboard(ctx, exp)
 	s.store.UpdateExperiment(ctx, exp)
+	s.publishStateChange(ctx, exp, pb.ExperimentStatus_PHASE_GENERATING)
+
+	if err := s.deployVariants(ctx, exp); err != nil {
+		s.logger.Error("failed to deploy variants",
+			zap.String("experiment_id", exp.ID),
+			zap.Error(err))
+
+		exp.Status.Phase = pb.ExperimentStatus_PHASE_FAILED
+		exp.Status.Message = fmt.Sprintf("Deployment failed: %v", err)
+		s.store.UpdateExperiment(ctx, exp)
+		s.publishStateChange(ctx, exp, pb.ExperimentStatus_PHASE_DEPLOYING)
+		s.exportResult(exp, exporter.VerdictFailed, "")
+		s.notifyResult(exp, exporter.VerdictFailed, "")
+		return
+	}
 
 	// TODO: Wait for deployment to complete
 	// This would monitor ArgoCD or Kubernetes for readiness
@@ -400,9 +518,143 @@ func (s *ExperimentService) cleanupExperimentResources(exp *models.Experiment) {
 	// 2. Clean up Git branches
 	// 3. Archive metrics data
 	s.logger.Info("cleaning up experiment resources", zap.String("experiment_id", exp.ID))
+
+	s.removeVariants(exp)
+	s.retireDashboard(exp)
+}
+
+func (s *ExperimentService) provisionDashboard(ctx context.Context, exp *models.Experiment) {
+	if s.dashboards == nil {
+		return
+	}
+
+	url, err := s.dashboards.Provision(ctx, exp.ID, exp.Name)
+	if err != nil {
+		s.logger.Warn("failed to provision experiment dashboard",
+			zap.String("experiment_id", exp.ID),
+			zap.Error(err))
+		return
+	}
+	exp.Status.DashboardUrl = url
+}
+
+func (s *ExperimentService) retireDashboard(exp *models.Experiment) {
+	if s.dashboards == nil || exp.Status.DashboardUrl == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := s.dashboards.Complete(ctx, exp.ID); err != nil {
+		s.logger.Warn("failed to retire experiment dashboard",
+			zap.String("experiment_id", exp.ID),
+			zap.Error(err))
+	}
+}
+
+func (s *ExperimentService) publishStateChange(ctx context.Context, exp *models.Experiment, previous pb.ExperimentStatus_Phase) {
+	if s.events == nil {
+		return
+	}
+
+	event := eventbus.ExperimentStateChanged{
+		ExperimentID: exp.ID,
+		Name:         exp.Name,
+		Owner:        exp.Owner,
+		Phase:        exp.Status.Phase.String(),
+		Message:      exp.Status.Message,
+	}
+	if previous != pb.ExperimentStatus_PHASE_UNSPECIFIED {
+		event.PreviousPhase = previous.String()
+	}
+
+	if err := eventbus.Publish(ctx, s.events, "phoenix-api", event); err != nil {
+		s.logger.Warn("failed to publish experiment state change",
+			zap.String("experiment_id", exp.ID),
+			zap.Error(err))
+	}
+}
+
+func (s *ExperimentService) exportResult(exp *models.Experiment, verdict, variant string) {
+	if s.exporter == nil {
+		return
+	}
+
+	summary := &exporter.ExperimentSummary{
+		ExperimentID: exp.ID,
+		Name:         exp.Name,
+		Owner:        exp.Owner,
+		Phase:        exp.Status.Phase.String(),
+		Verdict:      verdict,
+		Variant:      variant,
+		Message:      exp.Status.Message,
+		Timestamp:    time.Now(),
+	}
+	if m := exp.Status.Metrics; m != nil {
+		summary.BaselineCardinality = m.BaselineCardinality
+		summary.VariantCardinality = m.VariantCardinality
+		summary.CardinalityReductionPercent = m.CardinalityReductionPercent
+		summary.CostReductionPercent = m.CostReductionPercent
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := s.exporter.ExportExperimentResult(ctx, summary); err != nil {
+		s.logger.Warn("failed to export experiment result",
+			zap.String("experiment_id", exp.ID),
+			zap.String("exporter", s.exporter.Name()),
+			zap.Error(err))
+	}
+}
+
+func (s *ExperimentService) notifyResult(exp *models.Experiment, verdict, variant string) {
+	if s.notifier == nil {
+		return
+	}
+
+	n := &notifications.Notification{
+		Title:    fmt.Sprintf("Experiment %s %s", exp.Name, verdict),
+		Text:     exp.Status.Message,
+		Severity: notifications.SeverityInfo,
+		Source:   "phoenix-api",
+		URL:      exp.Status.DashboardUrl,
+		Fields: []notifications.Field{
+			{Name: "Experiment", Value: exp.ID},
+			{Name: "Owner", Value: exp.Owner},
+		},
+	}
+	if verdict == exporter.VerdictFailed || verdict == exporter.VerdictAborted {
+		n.Severity = notifications.SeverityWarning
+	}
+	if variant != "" {
+		n.Text = fmt.Sprintf("Variant %s was promoted.", variant)
+		n.Fields = append(n.Fields, notifications.Field{Name: "Variant", Value: variant})
+	}
+	if m := exp.Status.Metrics; m != nil {
+		n.Fields = append(n.Fields,
+			notifications.Field{Name: "Cardinality reduction", Value: fmt.Sprintf("%.1f%%", m.CardinalityReductionPercent)},
+			notifications.Field{Name: "Cost reduction", Value: fmt.Sprintf("%.1f%%", m.CostReductionPercent)},
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		s.logger.Warn("failed to send experiment notification",
+			zap.String("experiment_id", exp.ID),
+			zap.Error(err))
+	}
 }
 
 func (s *ExperimentService) isAdmin(ctx context.Context) bool {
+	return hasAdminRole(ctx)
+}
+
+// hasAdminRole reports whether the caller's token carries the admin role
+func hasAdminRole(ctx context.Context) bool {
 	claims, ok := ctx.Value("claims").(map[string]interface{})
 	if !ok {
 		return false
@@ -422,6 +674,14 @@ func (s *ExperimentService) isAdmin(ctx context.Context) bool {
 	return false
 }
 
+// snapshot copies an experiment, spec and status included
+func snapshot(exp *models.Experiment) *models.Experiment {
+	c := *exp
+	c.Spec = proto.Clone(exp.Spec).(*pb.ExperimentSpec)
+	c.Status = proto.Clone(exp.Status).(*pb.ExperimentStatus)
+	return &c
+}
+
 func (s *ExperimentService) modelToProto(exp *models.Experiment) *pb.Experiment {
 	return &pb.Experiment{
 		Id:          exp.ID,
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/federation"
+)
+
+// FederationService exposes the global view of every federated cluster
+type FederationService struct {
+	pb.UnimplementedFederationServiceServer
+	federator *federation.Federator
+	logger    *zap.Logger
+}
+
+func NewFederationService(federator *federation.Federator, logger *zap.Logger) *FederationService {
+	return &FederationService{
+		federator: federator,
+		logger:    logger,
+	}
+}
+
+func (s *FederationService) ListClusters(ctx context.Context, req *pb.ListClustersRequest) (*pb.ListClustersResponse, error) {
+	resp := &pb.ListClustersResponse{}
+	for _, state := range s.federator.Clusters() {
+		cluster := &pb.ClusterStatus{
+			Name:                state.Name,
+			Endpoint:            state.Endpoint,
+			Healthy:             state.Healthy,
+			Error:               state.Error,
+			AgentCount:          int32(len(state.Agents)),
+			CardinalityEstimate: state.CardinalityEstimate(),
+			ExperimentCount:     int32(len(state.Experiments)),
+		}
+		if !state.LastSync.IsZero() {
+			cluster.LastSync = timestamppb.New(state.LastSync)
+		}
+		for _, a := range state.Agents {
+			if a.Status == AgentStatusStale {
+				cluster.StaleAgentCount++
+			}
+		}
+		for _, e := range state.Experiments {
+			if e.Status.GetPhase() == pb.ExperimentStatus_PHASE_RUNNING {
+				cluster.RunningExperimentCount++
+			}
+		}
+
+		resp.Clusters = append(resp.Clusters, cluster)
+		resp.TotalAgents += cluster.AgentCount
+		resp.TotalCardinalityEstimate += cluster.CardinalityEstimate
+	}
+	return resp, nil
+}
+
+func (s *FederationService) ListFederatedAgents(ctx context.Context, req *pb.ListFederatedAgentsRequest) (*pb.ListFederatedAgentsResponse, error) {
+	// Agents have no owner to filter by, and this lists every cluster's
+	if !hasAdminRole(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can list agents across clusters")
+	}
+	states, err := s.states(req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListFederatedAgentsResponse{}
+	for _, state := range states {
+		for _, a := range state.Agents {
+			if req.Status != "" && a.Status != req.Status {
+				continue
+			}
+			resp.Agents = append(resp.Agents, &pb.FederatedAgent{Cluster: state.Name, Agent: a})
+		}
+	}
+	return resp, nil
+}
+
+func (s *FederationService) ListFederatedExperiments(ctx context.Context, req *pb.ListFederatedExperimentsRequest) (*pb.ListFederatedExperimentsResponse, error) {
+	states, err := s.states(req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// Non-admins only see their own experiments, as in ListExperiments
+	user, _ := ctx.Value("user").(string)
+	admin := hasAdminRole(ctx)
+
+	resp := &pb.ListFederatedExperimentsResponse{}
+	for _, state := range states {
+		for _, e := range state.Experiments {
+			if !admin && e.Owner != user {
+				continue
+			}
+			if req.Status != "" && e.Status.GetPhase().String() != req.Status {
+				continue
+			}
+			resp.Experiments = append(resp.Experiments, &pb.FederatedExperiment{Cluster: state.Name, Experiment: e})
+		}
+	}
+	return resp, nil
+}
+
+func (s *FederationService) RolloutExperiment(ctx context.Context, req *pb.RolloutExperimentRequest) (*pb.RolloutExperimentResponse, error) {
+	if !hasAdminRole(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can roll out experiments across clusters")
+	}
+	// Each cluster validates the spec itself and reports failures in its result
+	if req.Spec == nil {
+		return nil, status.Error(codes.InvalidArgument, "spec is required")
+	}
+
+	user, _ := ctx.Value("user").(string)
+	s.logger.Info("rolling out experiment across clusters",
+		zap.Strings("clusters", req.Clusters),
+		zap.Bool("stop_on_failure", req.StopOnFailure),
+		zap.String("user", user))
+
+	results, err := s.federator.Rollout(ctx, req.Spec, req.Clusters, req.StopOnFailure)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &pb.RolloutExperimentResponse{Results: make([]*pb.ClusterRolloutResult, len(results))}
+	for i, r := range results {
+		resp.Results[i] = &pb.ClusterRolloutResult{
+			Cluster:      r.Cluster,
+			Status:       r.Status,
+			ExperimentId: r.ExperimentID,
+			Error:        r.Error,
+		}
+	}
+	return resp, nil
+}
+
+// states returns every cluster, or only the named one
+func (s *FederationService) states(cluster string) ([]federation.ClusterState, error) {
+	if cluster == "" {
+		return s.federator.Clusters(), nil
+	}
+	state, ok := s.federator.Cluster(cluster)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown cluster: %s", cluster)
+	}
+	return []federation.ClusterState{state}, nil
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/exporter"
+	"github.com/phoenix/platform/pkg/guardrails"
+	"github.com/phoenix/platform/pkg/models"
+	"github.com/phoenix/platform/pkg/semconv"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+const (
+	guardrailPageSize     = 100
+	guardrailScanAttempts = 3
+)
+
+// guardrailMetrics maps each guardrail metric to the recording rule it is
+// evaluated against and the side of the threshold it must stay on
+var guardrailMetrics = map[pb.Guardrail_Metric]struct {
+	series string
+	bound  guardrails.Bound
+	max    float64
+}{
+	pb.Guardrail_METRIC_SIGNAL_PRESERVATION:       {semconv.RuleSignalPreservation, guardrails.Floor, 1},
+	pb.Guardrail_METRIC_CRITICAL_PROCESS_COVERAGE: {semconv.RuleCriticalProcessCoverage, guardrails.Floor, 100},
+	pb.Guardrail_METRIC_EXPORT_ERROR_RATIO:        {semconv.RuleExportErrorRatio, guardrails.Ceiling, 1},
+}
+
+// WithGuardrails aborts running experiments whose candidates violate one of
+// the guardrails in their spec; see WatchGuardrails
+func WithGuardrails(m *guardrails.Monitor) Option {
+	return func(s *ExperimentService) {
+		s.guardrails = m
+	}
+}
+
+// validateGuardrails checks the guardrails of a spec
+func validateGuardrails(spec *pb.ExperimentSpec) error {
+	seen := make(map[string]bool, len(spec.Guardrails))
+	for _, g := range spec.Guardrails {
+		if g.Name == "" {
+			return fmt.Errorf("guardrail name is required")
+		}
+		if seen[g.Name] {
+			return fmt.Errorf("duplicate guardrail %s", g.Name)
+		}
+		seen[g.Name] = true
+
+		metric, ok := guardrailMetrics[g.Metric]
+		if !ok {
+			return fmt.Errorf("guardrail %s: unknown metric %s", g.Name, g.Metric)
+		}
+		if g.Threshold < 0 || g.Threshold > metric.max {
+			return fmt.Errorf("guardrail %s: threshold must be between 0 and %g", g.Name, metric.max)
+		}
+		if g.Sustained != nil && g.Sustained.AsDuration() < 0 {
+			return fmt.Errorf("guardrail %s: sustained must not be negative", g.Name)
+		}
+	}
+	return nil
+}
+
+// WatchGuardrails evaluates the guardrails of every running experiment each
+// interval until ctx is cancelled. Aborts are claimed through the phase
+// store, so it does nothing without one.
+func (s *ExperimentService) WatchGuardrails(ctx context.Context, interval time.Duration) {
+	if s.guardrails == nil {
+		return
+	}
+	if s.phases == nil {
+		s.logger.Warn("guardrails are not watched: no phase store is configured")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.checkGuardrails(ctx); err != nil {
+			s.logger.Warn("failed to check experiment guardrails", zap.Error(err))
+		}
+	}
+}
+
+func (s *ExperimentService) checkGuardrails(ctx context.Context) error {
+	experiments, err := s.runningExperiments(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(experiments))
+	for _, exp := range experiments {
+		ids = append(ids, exp.ID)
+	}
+	s.guardrails.Retain(ids)
+
+	for _, exp := range experiments {
+		if len(exp.Spec.GetGuardrails()) > 0 {
+			s.checkExperimentGuardrails(ctx, exp)
+		}
+	}
+	return nil
+}
+
+// runningExperiments lists every running experiment before any is aborted.
+// Offset paging skips a row when an experiment ahead of the offset leaves
+// RUNNING during the scan, so the scan is repeated while the total moves and
+// the results are merged.
+func (s *ExperimentService) runningExperiments(ctx context.Context) ([]*models.Experiment, error) {
+	seen := make(map[string]bool)
+	var running []*models.Experiment
+
+	for attempt := 0; attempt < guardrailScanAttempts; attempt++ {
+		stable := true
+		firstTotal := -1
+		for offset := 0; ; offset += guardrailPageSize {
+			experiments, total, err := s.store.ListExperiments(ctx, store.ExperimentFilter{
+				Status: pb.ExperimentStatus_PHASE_RUNNING.String(),
+				Limit:  guardrailPageSize,
+				Offset: offset,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if firstTotal < 0 {
+				firstTotal = total
+			} else if total != firstTotal {
+				stable = false
+			}
+
+			for _, exp := range experiments {
+				if !seen[exp.ID] && exp.Status.GetPhase() == pb.ExperimentStatus_PHASE_RUNNING {
+					seen[exp.ID] = true
+					running = append(running, exp)
+				}
+			}
+
+			if len(experiments) == 0 || offset+len(experiments) >= total {
+				break
+			}
+		}
+		if stable {
+			break
+		}
+	}
+	return running, nil
+}
+
+func (s *ExperimentService) checkExperimentGuardrails(ctx context.Context, exp *models.Experiment) {
+	rails := make([]guardrails.Guardrail, 0, len(exp.Spec.Guardrails))
+	for _, g := range exp.Spec.Guardrails {
+		metric := guardrailMetrics[g.Metric]
+		rails = append(rails, guardrails.Guardrail{
+			Name:      g.Name,
+			Series:    metric.series,
+			Bound:     metric.bound,
+			Threshold: g.Threshold,
+			Sustained: g.Sustained.AsDuration(),
+		})
+	}
+
+	violations, err := s.guardrails.Evaluate(ctx, exp.ID, candidateVariants(exp.Spec), rails)
+	if err != nil {
+		s.logger.Warn("failed to evaluate guardrails", zap.String("experiment_id", exp.ID), zap.Error(err))
+		return
+	}
+	if len(violations) > 0 {
+		s.abortExperiment(ctx, exp, violations[0])
+	}
+}
+
+// abortExperiment rolls back the candidates of a running experiment after a
+// guardrail violation. The baseline keeps running so the hosts stay
+// monitored; it is removed with the experiment. Every replica evaluates
+// guardrails, so the abort is claimed with a RUNNING to ABORTED transition
+// first and only the replica that wins it acts.
+func (s *ExperimentService) abortExperiment(ctx context.Context, exp *models.Experiment, v guardrails.Violation) {
+	claimed, err := s.phases.TransitionPhase(ctx, exp.ID, pb.ExperimentStatus_PHASE_RUNNING.String(), pb.ExperimentStatus_PHASE_ABORTED.String())
+	if err != nil {
+		s.logger.Error("failed to abort experiment", zap.String("experiment_id", exp.ID), zap.Error(err))
+		return
+	}
+	s.guardrails.Forget(exp.ID)
+	if !claimed {
+		// Another replica aborted it, or it left RUNNING since it was listed
+		return
+	}
+
+	s.logger.Warn("guardrail violated, aborting experiment",
+		zap.String("experiment_id", exp.ID),
+		zap.String("guardrail", v.Guardrail),
+		zap.String("variant", v.Variant),
+		zap.Float64("value", v.Value),
+		zap.Float64("threshold", v.Threshold))
+
+	s.removeVariants(exp, candidateVariants(exp.Spec)...)
+
+	exp.Status.Phase = pb.ExperimentStatus_PHASE_ABORTED
+	exp.Status.Message = fmt.Sprintf("aborted: guardrail %s violated by %s", v.Guardrail, v)
+	exp.Status.GuardrailViolation = &pb.GuardrailViolation{
+		Guardrail: v.Guardrail,
+		Variant:   v.Variant,
+		Value:     v.Value,
+		Threshold: v.Threshold,
+		Since:     timestamppb.New(v.Since),
+	}
+	exp.UpdatedAt = time.Now()
+	if err := s.store.UpdateExperiment(ctx, exp); err != nil {
+		// The phase is already ABORTED; only the reason is lost
+		s.logger.Error("failed to save aborted experiment", zap.String("experiment_id", exp.ID), zap.Error(err))
+	}
+
+	s.publishStateChange(ctx, exp, pb.ExperimentStatus_PHASE_RUNNING)
+	s.exportResult(exp, exporter.VerdictAborted, "")
+	s.notifyResult(exp, exporter.VerdictAborted, "")
+	s.retireDashboard(exp)
+}
+
+func candidateVariants(spec *pb.ExperimentSpec) []string {
+	var candidates []string
+	for _, v := range spec.Variants {
+		if v.Name != baselineVariant {
+			candidates = append(candidates, v.Name)
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,35 @@
+// Package openapi serves the OpenAPI document generated from proto/experiment.proto.
+// phoenix.swagger.json is produced by protoc-gen-openapiv2 during "make generate"
+// and committed, so the API builds without the protobuf toolchain.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"net/http"
+
+	"github.com/phoenix/platform/pkg/httperr"
+)
+
+//go:embed phoenix.swagger.json
+var spec []byte
+
+// Spec returns the raw OpenAPI (swagger 2.0) document
+func Spec() []byte {
+	return spec
+}
+
+// Handler serves the OpenAPI document as JSON, or 503 if the build embedded
+// an empty document
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(bytes.TrimSpace(spec)) == 0 {
+			httperr.Write(w, r, httperr.New(httperr.CodeUpstreamUnavailable, "the API was built without an OpenAPI document, run make generate"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.WriteHeader(http.StatusOK)
+		w.Write(spec)
+	})
+}
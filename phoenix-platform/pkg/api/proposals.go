@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/models"
+	"github.com/phoenix/platform/pkg/store"
+	"github.com/phoenix/platform/pkg/utils"
+)
+
+// ProposeExperiment stores an experiment as a draft. Drafts are not
+// generated or deployed until an admin approves them.
+func (s *ExperimentService) ProposeExperiment(ctx context.Context, req *pb.ProposeExperimentRequest) (*pb.CreateExperimentResponse, error) {
+	if err := s.validateExperimentSpec(req.Spec); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid spec: %v", err)
+	}
+	if req.Source == "" {
+		return nil, status.Error(codes.InvalidArgument, "source is required")
+	}
+
+	user, ok := ctx.Value("user").(string)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not found in context")
+	}
+
+	exp := &models.Experiment{
+		ID:          utils.GenerateID("exp"),
+		Name:        req.Spec.Name,
+		Description: req.Spec.Description,
+		Owner:       user,
+		Spec:        req.Spec,
+		Status: &pb.ExperimentStatus{
+			Phase:   pb.ExperimentStatus_PHASE_DRAFT,
+			Message: "Proposed by " + req.Source + ", awaiting approval",
+			Proposal: &pb.Proposal{
+				Source:     req.Source,
+				Reason:     req.Reason,
+				Context:    req.Context,
+				ProposedAt: timestamppb.Now(),
+			},
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.store.CreateExperiment(ctx, exp); err != nil {
+		s.logger.Error("failed to create proposed experiment", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to create experiment: %v", err)
+	}
+	if err := s.recordSpecVersion(ctx, exp); err != nil {
+		s.logger.Error("experiment created without its spec version", zap.String("experiment_id", exp.ID), zap.Error(err))
+	}
+
+	s.logger.Info("experiment proposed",
+		zap.String("experiment_id", exp.ID),
+		zap.String("source", req.Source),
+		zap.String("reason", req.Reason))
+	s.publishStateChange(ctx, exp, pb.ExperimentStatus_PHASE_UNSPECIFIED)
+
+	return &pb.CreateExperimentResponse{
+		ExperimentId: exp.ID,
+		Status:       exp.Status.Phase.String(),
+	}, nil
+}
+
+// ApproveExperiment moves a draft to pending and starts generation
+func (s *ExperimentService) ApproveExperiment(ctx context.Context, req *pb.ReviewExperimentRequest) (*pb.Experiment, error) {
+	exp, err := s.reviewDraft(ctx, req, pb.ExperimentStatus_PHASE_PENDING, "Approved")
+	if err != nil {
+		return nil, err
+	}
+
+	// Trigger async generation
+	go s.generateArtifacts(exp)
+
+	return s.modelToProto(exp), nil
+}
+
+// RejectExperiment closes a draft without running it
+func (s *ExperimentService) RejectExperiment(ctx context.Context, req *pb.ReviewExperimentRequest) (*pb.Experiment, error) {
+	exp, err := s.reviewDraft(ctx, req, pb.ExperimentStatus_PHASE_REJECTED, "Rejected")
+	if err != nil {
+		return nil, err
+	}
+	return s.modelToProto(exp), nil
+}
+
+func (s *ExperimentService) reviewDraft(ctx context.Context, req *pb.ReviewExperimentRequest, phase pb.ExperimentStatus_Phase, verb string) (*models.Experiment, error) {
+	if s.phases == nil {
+		return nil, status.Error(codes.Unimplemented, "proposal review is not configured")
+	}
+
+	exp, err := s.store.GetExperiment(ctx, req.ExperimentId)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, status.Error(codes.NotFound, "experiment not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get experiment: %v", err)
+	}
+
+	// Proposals come from automation, so a human admin other than the
+	// proposer has to review them. API keys belong to automation.
+	if !s.isAdmin(ctx) || isAPIKey(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins signed in as a user can review proposed experiments")
+	}
+	user, _ := ctx.Value("user").(string)
+	if user == "" || user == exp.Owner {
+		return nil, status.Error(codes.PermissionDenied, "proposed experiments must be reviewed by someone other than the proposer")
+	}
+
+	if exp.Status.Phase != pb.ExperimentStatus_PHASE_DRAFT {
+		return nil, status.Errorf(codes.FailedPrecondition, "experiment is %s, not a draft", exp.Status.Phase)
+	}
+
+	// Claim the draft first so that only one concurrent review wins
+	claimed, err := s.phases.TransitionPhase(ctx, exp.ID, pb.ExperimentStatus_PHASE_DRAFT.String(), phase.String())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update experiment: %v", err)
+	}
+	if !claimed {
+		return nil, status.Error(codes.FailedPrecondition, "experiment is no longer a draft, it was reviewed concurrently")
+	}
+
+	if exp.Status.Proposal == nil {
+		exp.Status.Proposal = &pb.Proposal{}
+	}
+	exp.Status.Proposal.ReviewedBy = user
+	exp.Status.Proposal.ReviewedAt = timestamppb.Now()
+	exp.Status.Proposal.ReviewComment = req.Comment
+
+	exp.Status.Phase = phase
+	exp.Status.Message = verb + " by " + user
+	exp.UpdatedAt = time.Now()
+
+	if err := s.store.UpdateExperiment(ctx, exp); err != nil {
+		// Release the claim so the draft can be reviewed again
+		if _, rerr := s.phases.TransitionPhase(ctx, exp.ID, phase.String(), pb.ExperimentStatus_PHASE_DRAFT.String()); rerr != nil {
+			s.logger.Error("failed to release review of proposed experiment",
+				zap.String("experiment_id", exp.ID), zap.Error(rerr))
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update experiment: %v", err)
+	}
+
+	s.logger.Info("proposed experiment reviewed",
+		zap.String("experiment_id", exp.ID),
+		zap.String("decision", phase.String()),
+		zap.String("user", user))
+	s.publishStateChange(ctx, exp, pb.ExperimentStatus_PHASE_DRAFT)
+
+	return exp, nil
+}
+
+// isAPIKey reports whether the caller authenticated with an API key rather
+// than a user session
+func isAPIKey(ctx context.Context) bool {
+	claims, _ := ctx.Value("claims").(map[string]interface{})
+	_, ok := claims["api_key_id"]
+	return ok
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/phoenix/platform/pipelines"
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/deploy"
+)
+
+const (
+	baselineVariant = "baseline"
+
+	// maxCandidates bounds A/B/n experiments; every variant runs its own
+	// collector DaemonSet
+	maxCandidates = 8
+)
+
+// variantNamePattern keeps variant names usable in Kubernetes resource names
+var variantNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,18}[a-z0-9])?$`)
+
+func (s *ExperimentService) validateExperimentSpec(spec *pb.ExperimentSpec) error {
+	if spec == nil {
+		return fmt.Errorf("spec is required")
+	}
+
+	if spec.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if len(spec.Variants) < 2 {
+		return fmt.Errorf("a baseline and at least one candidate variant are required")
+	}
+	if len(spec.Variants) > maxCandidates+1 {
+		return fmt.Errorf("at most %d candidate variants are supported", maxCandidates)
+	}
+
+	// Validate variants
+	seen := make(map[string]bool, len(spec.Variants))
+	for _, v := range spec.Variants {
+		if !variantNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("variant name %q must be a lowercase DNS label of at most 20 characters", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("duplicate variant %s", v.Name)
+		}
+		seen[v.Name] = true
+
+		if v.Pipeline == nil || len(v.Pipeline.Nodes) == 0 {
+			return fmt.Errorf("variant %s must have at least one processor node", v.Name)
+		}
+		if err := pipelines.Validate(v.Pipeline); err != nil {
+			return fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+	}
+
+	if !seen[baselineVariant] {
+		return fmt.Errorf("must have a %s variant", baselineVariant)
+	}
+
+	if spec.Duration != nil && spec.Duration.AsDuration() <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+
+	if spec.SuccessCriteria != nil {
+		c := spec.SuccessCriteria
+		criteria := []struct {
+			name  string
+			value float64
+			ratio bool
+		}{
+			{"min_cardinality_reduction", c.MinCardinalityReduction, true},
+			{"max_critical_process_loss", c.MaxCriticalProcessLoss, true},
+			{"max_latency_increase", c.MaxLatencyIncrease, false},
+			{"min_cost_reduction", c.MinCostReduction, true},
+		}
+		for _, cr := range criteria {
+			if cr.value < 0 {
+				return fmt.Errorf("success criterion %s must not be negative", cr.name)
+			}
+			if cr.ratio && cr.value > 1 {
+				return fmt.Errorf("success criterion %s is a ratio and must not exceed 1", cr.name)
+			}
+		}
+	}
+
+	if err := validateGuardrails(spec); err != nil {
+		return err
+	}
+
+	if err := s.validateTargetEnvironment(spec.TargetEnvironment); err != nil {
+		return err
+	}
+
+	nodes, err := assignVariantNodes(spec)
+	if err != nil {
+		return err
+	}
+	// Hosts are only reached by name; there is no selector to fall back to
+	if spec.TargetEnvironment == deploy.EnvironmentVM {
+		for _, v := range spec.Variants {
+			if len(nodes[v.Name]) == 0 {
+				return fmt.Errorf("vm experiments need target nodes; variant %s has none", v.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTargetEnvironment rejects environments this instance cannot deploy
+// to
+func (s *ExperimentService) validateTargetEnvironment(environment string) error {
+	switch environment {
+	case "", deploy.EnvironmentKubernetes, deploy.EnvironmentVM:
+	default:
+		return fmt.Errorf("unknown target environment %q", environment)
+	}
+	_, err := s.backendFor(environment)
+	return err
+}
+
+// targetNodes returns the nodes selected for an experiment, merging the
+// deprecated top-level target_nodes into the target selector
+func targetNodes(spec *pb.ExperimentSpec) []string {
+	seen := make(map[string]bool)
+	var nodes []string
+	add := func(list []string) {
+		for _, n := range list {
+			if !seen[n] {
+				seen[n] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	if spec.Target != nil {
+		add(spec.Target.Nodes)
+	}
+	add(spec.TargetNodes)
+	sort.Strings(nodes)
+	return nodes
+}
+
+// assignVariantNodes gives each variant its own node subset so variants can
+// be compared side by side. Variants listing nodes keep them; the remaining
+// target nodes are dealt round-robin to the other variants. Without target
+// nodes every variant runs on all nodes matching the selector labels.
+func assignVariantNodes(spec *pb.ExperimentSpec) (map[string][]string, error) {
+	assigned := make(map[string][]string, len(spec.Variants))
+	owner := make(map[string]string)
+
+	var unassigned []string
+	for _, v := range spec.Variants {
+		if len(v.Nodes) == 0 {
+			unassigned = append(unassigned, v.Name)
+			continue
+		}
+		for _, n := range v.Nodes {
+			if other, ok := owner[n]; ok && other != v.Name {
+				return nil, fmt.Errorf("node %s is assigned to both %s and %s", n, other, v.Name)
+			}
+			owner[n] = v.Name
+		}
+		assigned[v.Name] = append([]string(nil), v.Nodes...)
+	}
+
+	var free []string
+	for _, n := range targetNodes(spec) {
+		if _, ok := owner[n]; !ok {
+			free = append(free, n)
+		}
+	}
+
+	if len(unassigned) == 0 || (len(free) == 0 && len(owner) == 0) {
+		return assigned, nil
+	}
+	if len(free) < len(unassigned) {
+		return nil, fmt.Errorf("%d target nodes left for %d variants without nodes; each variant needs at least one", len(free), len(unassigned))
+	}
+
+	for i, n := range free {
+		name := unassigned[i%len(unassigned)]
+		assigned[name] = append(assigned[name], n)
+	}
+	return assigned, nil
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/models"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+func (s *ExperimentService) ListExperimentSpecVersions(ctx context.Context, req *pb.ListExperimentSpecVersionsRequest) (*pb.ListExperimentSpecVersionsResponse, error) {
+	if s.specVersions == nil {
+		return nil, status.Error(codes.Unimplemented, "spec versioning is not configured")
+	}
+
+	if _, err := s.getAccessibleExperiment(ctx, req.ExperimentId); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.specVersions.ListSpecVersions(ctx, req.ExperimentId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list spec versions: %v", err)
+	}
+
+	resp := &pb.ListExperimentSpecVersionsResponse{}
+	for _, v := range versions {
+		spec := &pb.ExperimentSpec{}
+		if err := protojson.Unmarshal(v.Spec, spec); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode spec version %d: %v", v.Version, err)
+		}
+		resp.Versions = append(resp.Versions, &pb.ExperimentSpecVersion{
+			Version:   int32(v.Version),
+			Spec:      spec,
+			CreatedBy: v.CreatedBy,
+			CreatedAt: timestamppb.New(v.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// recordSpecVersion keeps the spec an experiment was created or updated with
+func (s *ExperimentService) recordSpecVersion(ctx context.Context, exp *models.Experiment) error {
+	if s.specVersions == nil {
+		return nil
+	}
+
+	spec, err := protojson.Marshal(exp.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode spec version: %w", err)
+	}
+
+	user, _ := ctx.Value("user").(string)
+	if err := s.specVersions.SaveSpecVersion(ctx, &store.SpecVersion{
+		ExperimentID: exp.ID,
+		Spec:         spec,
+		CreatedBy:    user,
+	}); err != nil {
+		return fmt.Errorf("failed to save spec version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/eventbus"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// statusPageSize is used when paging through agents and experiments
+const statusPageSize = 500
+
+// baselineMode is the mode of agents running the unoptimized pipeline
+const baselineMode = "baseline"
+
+// StatusService aggregates fleet, experiment and anomaly state into a single
+// response. The result is cached for a short time because the dashboard home
+// page polls it.
+type StatusService struct {
+	pb.UnimplementedStatusServiceServer
+	experiments   store.ExperimentStore
+	agents        store.AgentStore
+	staleAfter    time.Duration
+	ttl           time.Duration
+	anomalyWindow time.Duration
+	logger        *zap.Logger
+
+	mu        sync.Mutex
+	cached    *pb.PlatformStatus
+	cachedAt  time.Time
+	anomalies []time.Time
+}
+
+// NewStatusService creates the service. Responses are reused for ttl and an
+// anomaly counts as active for anomalyWindow after it is reported.
+func NewStatusService(experiments store.ExperimentStore, agents store.AgentStore, staleAfter, ttl, anomalyWindow time.Duration, logger *zap.Logger) *StatusService {
+	return &StatusService{
+		experiments:   experiments,
+		agents:        agents,
+		staleAfter:    staleAfter,
+		ttl:           ttl,
+		anomalyWindow: anomalyWindow,
+		logger:        logger,
+	}
+}
+
+// TrackAnomalies counts AnomalyDetected events from the bus until ctx is
+// cancelled
+func (s *StatusService) TrackAnomalies(ctx context.Context, bus eventbus.Bus) error {
+	return eventbus.Handle(ctx, bus, s.logger, func(_ context.Context, event eventbus.Event, _ eventbus.AnomalyDetected) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.anomalies = append(s.anomalies, event.Time)
+		return nil
+	})
+}
+
+func (s *StatusService) GetPlatformStatus(ctx context.Context, req *pb.GetPlatformStatusRequest) (*pb.PlatformStatus, error) {
+	s.mu.Lock()
+	if s.cached != nil && time.Since(s.cachedAt) < s.ttl {
+		cached := proto.Clone(s.cached).(*pb.PlatformStatus)
+		s.mu.Unlock()
+		return cached, nil
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	resp := &pb.PlatformStatus{
+		ModeDistribution:   map[string]int32{},
+		ExperimentsByPhase: map[string]int32{},
+		GeneratedAt:        timestamppb.New(now),
+	}
+
+	if err := s.addAgents(ctx, resp, now); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list agents: %v", err)
+	}
+	if err := s.addExperiments(ctx, resp, now); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list experiments: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop anomalies that fell out of the window
+	cutoff := now.Add(-s.anomalyWindow)
+	active := s.anomalies[:0]
+	for _, t := range s.anomalies {
+		if t.After(cutoff) {
+			active = append(active, t)
+		}
+	}
+	s.anomalies = active
+	resp.ActiveAnomalies = int32(len(active))
+
+	s.cached = resp
+	s.cachedAt = now
+	return proto.Clone(resp).(*pb.PlatformStatus), nil
+}
+
+func (s *StatusService) addAgents(ctx context.Context, resp *pb.PlatformStatus, now time.Time) error {
+	for offset := 0; ; offset += statusPageSize {
+		agents, total, err := s.agents.ListAgents(ctx, store.AgentFilter{Limit: statusPageSize, Offset: offset})
+		if err != nil {
+			return err
+		}
+
+		for _, a := range agents {
+			resp.AgentCount++
+			if now.Sub(a.LastSeen) > s.staleAfter {
+				resp.StaleAgentCount++
+				continue
+			}
+			resp.ModeDistribution[a.Mode]++
+			resp.CardinalityEstimate += a.CardinalityEstimate
+			if a.Mode != "" && a.Mode != baselineMode {
+				resp.OptimizedCardinalityEstimate += a.CardinalityEstimate
+			}
+		}
+
+		if len(agents) == 0 || offset+len(agents) >= total {
+			return nil
+		}
+	}
+}
+
+// addExperiments counts experiments by phase. Savings come from promoted
+// candidates only; a completed experiment whose candidate was not rolled out
+// saves nothing.
+func (s *StatusService) addExperiments(ctx context.Context, resp *pb.PlatformStatus, now time.Time) error {
+	for offset := 0; ; offset += statusPageSize {
+		experiments, total, err := s.experiments.ListExperiments(ctx, store.ExperimentFilter{Limit: statusPageSize, Offset: offset})
+		if err != nil {
+			return err
+		}
+
+		for _, exp := range experiments {
+			phase := exp.Status.GetPhase()
+			resp.ExperimentsByPhase[phase.String()]++
+			switch phase {
+			case pb.ExperimentStatus_PHASE_RUNNING:
+				resp.RunningExperiments++
+			case pb.ExperimentStatus_PHASE_COMPLETED:
+				promoted := exp.Status.GetPromotedVariant()
+				m := exp.Status.GetMetrics()
+				if promoted == "" || promoted == baselineVariant || m == nil || m.BaselineCostPerHour <= m.VariantCostPerHour {
+					continue
+				}
+				saved := m.BaselineCostPerHour - m.VariantCostPerHour
+				resp.CostSavingsPerHour += saved
+				if since := exp.Status.GetPromotedAt(); since != nil && now.After(since.AsTime()) {
+					resp.CostSavingsToDate += saved * now.Sub(since.AsTime()).Hours()
+				}
+			}
+		}
+
+		if len(experiments) == 0 || offset+len(experiments) >= total {
+			return nil
+		}
+	}
+}
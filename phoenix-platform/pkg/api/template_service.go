@@ -0,0 +1,410 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/bundle"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// templateBundleVersion is the TemplateBundleContents format this instance
+// writes and accepts
+const templateBundleVersion = 1
+
+// templateNamePattern restricts namespaces and template names to DNS labels so
+// they are safe in URLs
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// TemplateService publishes experiment specs to a catalog, shares them
+// between instances as signed bundles and creates experiments from them
+type TemplateService struct {
+	pb.UnimplementedTemplateServiceServer
+	store       store.TemplateStore
+	experiments *ExperimentService
+	signer      *bundle.Signer
+	verifier    *bundle.Verifier
+	bundleTTL   time.Duration
+	logger      *zap.Logger
+}
+
+// NewTemplateService creates the catalog. signer may be nil, in which case
+// exports are disabled; exported bundles expire after bundleTTL. Bundles are
+// only imported when signed by a key the verifier trusts, and the instance
+// always trusts its own key.
+func NewTemplateService(store store.TemplateStore, experiments *ExperimentService, signer *bundle.Signer, verifier *bundle.Verifier, bundleTTL time.Duration, logger *zap.Logger) *TemplateService {
+	if verifier == nil {
+		verifier = bundle.NewVerifier()
+	}
+	if signer != nil {
+		verifier.Trust(signer.Public())
+	}
+	return &TemplateService{
+		store:       store,
+		experiments: experiments,
+		signer:      signer,
+		verifier:    verifier,
+		bundleTTL:   bundleTTL,
+		logger:      logger,
+	}
+}
+
+// PublishTemplate copies an experiment's spec into the catalog. Node
+// targeting is dropped since it only makes sense for the original experiment.
+func (s *TemplateService) PublishTemplate(ctx context.Context, req *pb.PublishTemplateRequest) (*pb.Template, error) {
+	if err := validateTemplateName(req.Namespace, req.Name); err != nil {
+		return nil, err
+	}
+
+	exp, err := s.experiments.getAccessibleExperiment(ctx, req.ExperimentId)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := proto.Clone(exp.Spec).(*pb.ExperimentSpec)
+	spec.Target = nil
+	spec.TargetNodes = nil
+	for _, v := range spec.Variants {
+		v.Nodes = nil
+	}
+
+	description := req.Description
+	if description == "" {
+		description = exp.Description
+	}
+
+	user, _ := ctx.Value("user").(string)
+	t, err := s.save(ctx, &pb.Template{
+		Namespace:   req.Namespace,
+		Name:        req.Name,
+		Description: description,
+		Tags:        req.Tags,
+		Spec:        spec,
+		CreatedBy:   user,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("template published",
+		zap.String("namespace", t.Namespace),
+		zap.String("name", t.Name),
+		zap.String("experiment_id", exp.ID),
+		zap.String("user", user))
+	return t, nil
+}
+
+func (s *TemplateService) ListTemplates(ctx context.Context, req *pb.ListTemplatesRequest) (*pb.ListTemplatesResponse, error) {
+	templates, err := s.store.ListTemplates(ctx, store.TemplateFilter{
+		Namespace: req.Namespace,
+		Tag:       req.Tag,
+	})
+	if err != nil {
+		s.logger.Error("failed to list templates", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list templates")
+	}
+
+	resp := &pb.ListTemplatesResponse{Templates: make([]*pb.Template, 0, len(templates))}
+	for _, t := range templates {
+		pbTemplate, err := templateToProto(t)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode template %s/%s: %v", t.Namespace, t.Name, err)
+		}
+		resp.Templates = append(resp.Templates, pbTemplate)
+	}
+	return resp, nil
+}
+
+func (s *TemplateService) GetTemplate(ctx context.Context, req *pb.GetTemplateRequest) (*pb.Template, error) {
+	return s.get(ctx, req.Namespace, req.Name)
+}
+
+// ExportTemplates signs a bundle of templates from one namespace that another
+// instance can import
+func (s *TemplateService) ExportTemplates(ctx context.Context, req *pb.ExportTemplatesRequest) (*pb.TemplateBundle, error) {
+	if s.signer == nil {
+		return nil, status.Error(codes.FailedPrecondition, "template export requires a signing key, see TEMPLATES_SIGNING_KEY_FILE")
+	}
+
+	var templates []*pb.Template
+	if len(req.Names) > 0 {
+		for _, name := range req.Names {
+			t, err := s.get(ctx, req.Namespace, name)
+			if err != nil {
+				return nil, err
+			}
+			templates = append(templates, t)
+		}
+	} else {
+		resp, err := s.ListTemplates(ctx, &pb.ListTemplatesRequest{Namespace: req.Namespace})
+		if err != nil {
+			return nil, err
+		}
+		templates = resp.Templates
+	}
+	if len(templates) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no templates in namespace %s", req.Namespace)
+	}
+
+	// Usage counts are local to each instance
+	for _, t := range templates {
+		t.UsageCount = 0
+	}
+
+	user, _ := ctx.Value("user").(string)
+	now := time.Now()
+	expiresAt := now.Add(s.bundleTTL).Truncate(time.Second)
+	payload, err := protojson.Marshal(&pb.TemplateBundleContents{
+		FormatVersion: templateBundleVersion,
+		Templates:     templates,
+		ExportedBy:    user,
+		ExportedAt:    timestamppb.New(now),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode bundle: %v", err)
+	}
+
+	s.logger.Info("templates exported",
+		zap.String("namespace", req.Namespace),
+		zap.Int("count", len(templates)),
+		zap.String("user", user))
+	return &pb.TemplateBundle{
+		Payload:   payload,
+		Signature: s.signer.Sign(payload, expiresAt),
+		KeyId:     s.signer.KeyID(),
+		ExpiresAt: timestamppb.New(expiresAt),
+	}, nil
+}
+
+// ImportTemplates verifies a bundle and saves its templates, moving them to
+// the namespaces given by the mapping. The templates are saved all or none.
+func (s *TemplateService) ImportTemplates(ctx context.Context, req *pb.ImportTemplatesRequest) (*pb.ImportTemplatesResponse, error) {
+	if !hasAdminRole(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "only admins can import templates")
+	}
+	if req.Bundle == nil {
+		return nil, status.Error(codes.InvalidArgument, "bundle is required")
+	}
+
+	var expiresAt time.Time
+	if req.Bundle.ExpiresAt != nil {
+		expiresAt = req.Bundle.ExpiresAt.AsTime()
+	}
+	if err := s.verifier.Verify(req.Bundle.Payload, req.Bundle.Signature, req.Bundle.KeyId, expiresAt); err != nil {
+		if errors.Is(err, bundle.ErrUntrustedKey) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	contents := &pb.TemplateBundleContents{}
+	if err := protojson.Unmarshal(req.Bundle.Payload, contents); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid bundle: %v", err)
+	}
+	if contents.FormatVersion != templateBundleVersion {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported bundle format version %d", contents.FormatVersion)
+	}
+
+	user, _ := ctx.Value("user").(string)
+	records := make([]*store.Template, 0, len(contents.Templates))
+	for _, t := range contents.Templates {
+		if mapped, ok := req.NamespaceMapping[t.Namespace]; ok {
+			t.Namespace = mapped
+		}
+		if err := validateTemplateName(t.Namespace, t.Name); err != nil {
+			return nil, err
+		}
+		if err := s.experiments.validateExperimentSpec(t.Spec); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "template %s/%s: invalid spec: %v", t.Namespace, t.Name, err)
+		}
+		t.ImportedFrom = req.Bundle.KeyId
+		t.CreatedBy = user
+
+		record, err := templateToRecord(t)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	err := s.store.SaveTemplates(ctx, records, req.Overwrite)
+	if errors.Is(err, store.ErrTemplateExists) {
+		return nil, status.Errorf(codes.AlreadyExists, "%v; nothing was imported", err)
+	}
+	if err != nil {
+		s.logger.Error("failed to import templates", zap.String("key_id", req.Bundle.KeyId), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to import templates")
+	}
+
+	resp := &pb.ImportTemplatesResponse{Templates: make([]*pb.Template, 0, len(records))}
+	for i, t := range contents.Templates {
+		resp.Templates = append(resp.Templates, withRecordState(t, records[i]))
+	}
+
+	s.logger.Info("templates imported",
+		zap.String("key_id", req.Bundle.KeyId),
+		zap.String("exported_by", contents.ExportedBy),
+		zap.Int("count", len(resp.Templates)),
+		zap.String("user", user))
+	return resp, nil
+}
+
+// CreateExperimentFromTemplate creates an experiment from a template's spec,
+// with per-variant parameter overrides and the caller's node targeting
+func (s *TemplateService) CreateExperimentFromTemplate(ctx context.Context, req *pb.CreateExperimentFromTemplateRequest) (*pb.CreateExperimentResponse, error) {
+	t, err := s.get(ctx, req.Namespace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := t.Spec
+	spec.Name = req.ExperimentName
+	spec.Description = req.Description
+	if spec.Description == "" {
+		spec.Description = t.Description
+	}
+	spec.Target = req.Target
+	if req.TargetEnvironment != "" {
+		spec.TargetEnvironment = req.TargetEnvironment
+	}
+	if req.Duration != nil {
+		spec.Duration = req.Duration
+	}
+	if err := applyTemplateParameters(spec, req.Parameters); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.experiments.CreateExperiment(ctx, &pb.CreateExperimentRequest{Spec: spec})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.IncrementTemplateUsage(ctx, t.Namespace, t.Name); err != nil {
+		s.logger.Warn("failed to count template usage",
+			zap.String("namespace", t.Namespace),
+			zap.String("name", t.Name),
+			zap.Error(err))
+	}
+	return resp, nil
+}
+
+// applyTemplateParameters sets parameters keyed by "<variant>.<parameter>"
+func applyTemplateParameters(spec *pb.ExperimentSpec, parameters map[string]string) error {
+	variants := make(map[string]*pb.PipelineVariant, len(spec.Variants))
+	for _, v := range spec.Variants {
+		variants[v.Name] = v
+	}
+
+	for key, value := range parameters {
+		name, param, ok := strings.Cut(key, ".")
+		if !ok || param == "" {
+			return status.Errorf(codes.InvalidArgument, "parameter %q must be of the form <variant>.<parameter>", key)
+		}
+		v, ok := variants[name]
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "parameter %q: template has no variant %s", key, name)
+		}
+		if v.Parameters == nil {
+			v.Parameters = make(map[string]string)
+		}
+		v.Parameters[param] = value
+	}
+	return nil
+}
+
+func (s *TemplateService) get(ctx context.Context, namespace, name string) (*pb.Template, error) {
+	t, err := s.store.GetTemplate(ctx, namespace, name)
+	if err == store.ErrTemplateNotFound {
+		return nil, status.Errorf(codes.NotFound, "template %s/%s not found", namespace, name)
+	}
+	if err != nil {
+		s.logger.Error("failed to get template", zap.String("namespace", namespace), zap.String("name", name), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to get template")
+	}
+
+	pbTemplate, err := templateToProto(t)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode template %s/%s: %v", namespace, name, err)
+	}
+	return pbTemplate, nil
+}
+
+func (s *TemplateService) save(ctx context.Context, t *pb.Template, overwrite bool) (*pb.Template, error) {
+	record, err := templateToRecord(t)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.SaveTemplate(ctx, record, overwrite)
+	if err == store.ErrTemplateExists {
+		return nil, status.Errorf(codes.AlreadyExists, "template %s/%s already exists", t.Namespace, t.Name)
+	}
+	if err != nil {
+		s.logger.Error("failed to save template", zap.String("namespace", t.Namespace), zap.String("name", t.Name), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to save template")
+	}
+	return withRecordState(t, record), nil
+}
+
+func templateToRecord(t *pb.Template) (*store.Template, error) {
+	spec, err := protojson.Marshal(t.Spec)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode template spec: %v", err)
+	}
+	return &store.Template{
+		Namespace:    t.Namespace,
+		Name:         t.Name,
+		Description:  t.Description,
+		Tags:         t.Tags,
+		Spec:         spec,
+		ImportedFrom: t.ImportedFrom,
+		CreatedBy:    t.CreatedBy,
+	}, nil
+}
+
+// withRecordState copies the fields the store sets on save into t
+func withRecordState(t *pb.Template, record *store.Template) *pb.Template {
+	t.UsageCount = int32(record.UsageCount)
+	t.CreatedAt = timestamppb.New(record.CreatedAt)
+	t.UpdatedAt = timestamppb.New(record.UpdatedAt)
+	return t
+}
+
+func validateTemplateName(namespace, name string) error {
+	if !templateNamePattern.MatchString(namespace) {
+		return status.Errorf(codes.InvalidArgument, "namespace %q must be a lowercase DNS label", namespace)
+	}
+	if !templateNamePattern.MatchString(name) {
+		return status.Errorf(codes.InvalidArgument, "template name %q must be a lowercase DNS label", name)
+	}
+	return nil
+}
+
+func templateToProto(t *store.Template) (*pb.Template, error) {
+	spec := &pb.ExperimentSpec{}
+	if err := protojson.Unmarshal(t.Spec, spec); err != nil {
+		return nil, err
+	}
+	return &pb.Template{
+		Namespace:    t.Namespace,
+		Name:         t.Name,
+		Description:  t.Description,
+		Tags:         t.Tags,
+		Spec:         spec,
+		UsageCount:   int32(t.UsageCount),
+		ImportedFrom: t.ImportedFrom,
+		CreatedBy:    t.CreatedBy,
+		CreatedAt:    timestamppb.New(t.CreatedAt),
+		UpdatedAt:    timestamppb.New(t.UpdatedAt),
+	}, nil
+}
@@ -0,0 +1,225 @@
+// Package apikeys issues and verifies machine credentials for the platform
+// API. Keys are separate from user JWTs, carry scopes, belong to a tenant and
+// are stored as hashes only.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/phoenix/platform/pkg/audit"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// tokenPrefix marks a bearer token as an API key rather than a JWT
+const tokenPrefix = "phx_"
+
+// Scopes granted to a key. write implies read; admin implies both, the
+// admin role and access to the admin services.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// ValidScope reports whether scope is one of the known scopes
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeRead, ScopeWrite, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// Generate creates a new key. The token is returned to the caller once; only
+// the prefix and hash are stored.
+func Generate() (token, prefix, hash string, err error) {
+	public := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(public); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	prefix = hex.EncodeToString(public)
+	token = tokenPrefix + prefix + "_" + hex.EncodeToString(secret)
+	return token, prefix, Hash(token), nil
+}
+
+// NewID returns a random key ID
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "key-" + hex.EncodeToString(b), nil
+}
+
+// Hash is the stored form of a token
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// parse extracts the lookup prefix of an API key token
+func parse(token string) (string, bool) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(token, tokenPrefix), "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// adminServices can only be called with the admin scope, whether or not the
+// method changes state: key management and the audit log
+var adminServices = []string{
+	"/phoenix.v1.APIKeyService/",
+	"/phoenix.v1.AuditService/",
+}
+
+func isAdminMethod(fullMethod string) bool {
+	for _, service := range adminServices {
+		if strings.HasPrefix(fullMethod, service) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether scopes permit a call to fullMethod
+func allows(scopes []string, fullMethod string) bool {
+	admin := isAdminMethod(fullMethod)
+	for _, scope := range scopes {
+		switch scope {
+		case ScopeAdmin:
+			return true
+		case ScopeWrite:
+			if !admin {
+				return true
+			}
+		case ScopeRead:
+			if !admin && !audit.IsMutating(fullMethod) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authenticator accepts API keys in the Authorization header and hands every
+// other token to the JWT interceptors
+type Authenticator struct {
+	store  store.APIKeyStore
+	logger *zap.Logger
+}
+
+func NewAuthenticator(keys store.APIKeyStore, logger *zap.Logger) *Authenticator {
+	return &Authenticator{store: keys, logger: logger}
+}
+
+// UnaryInterceptor authenticates API key calls and delegates the rest to next
+func (a *Authenticator) UnaryInterceptor(next grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		token, ok := apiKeyToken(ctx)
+		if !ok {
+			return next(ctx, req, info, handler)
+		}
+		ctx, err := a.authenticate(ctx, token, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor authenticates API key streams and delegates the rest to next
+func (a *Authenticator) StreamInterceptor(next grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		token, ok := apiKeyToken(ss.Context())
+		if !ok {
+			return next(srv, ss, info, handler)
+		}
+		ctx, err := a.authenticate(ss.Context(), token, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate verifies the key and returns a context carrying the same
+// "user" and "claims" values the JWT interceptors set
+func (a *Authenticator) authenticate(ctx context.Context, token, fullMethod string) (context.Context, error) {
+	prefix, _ := parse(token)
+	key, err := a.store.UseAPIKey(ctx, prefix)
+	if err == store.ErrAPIKeyNotFound {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	if err != nil {
+		a.logger.Error("failed to look up API key", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to verify API key")
+	}
+	if subtle.ConstantTimeCompare([]byte(Hash(token)), []byte(key.Hash)) != 1 {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	if !allows(key.Scopes, fullMethod) {
+		return nil, status.Errorf(codes.PermissionDenied, "API key %s does not have the scope for %s", key.ID, fullMethod)
+	}
+
+	roles := []string{}
+	for _, scope := range key.Scopes {
+		if scope == ScopeAdmin {
+			roles = append(roles, "admin")
+		}
+	}
+	claims := map[string]interface{}{
+		"roles":      roles,
+		"scopes":     key.Scopes,
+		"tenant":     key.Tenant,
+		"api_key_id": key.ID,
+	}
+	ctx = context.WithValue(ctx, "user", key.Tenant+"/"+key.Name)
+	ctx = context.WithValue(ctx, "claims", claims)
+	return ctx, nil
+}
+
+func apiKeyToken(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	token := strings.TrimSpace(values[0])
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	if _, ok := parse(token); !ok {
+		return "", false
+	}
+	return token, true
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
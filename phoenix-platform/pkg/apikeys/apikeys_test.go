@@ -0,0 +1,216 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/phoenix/platform/pkg/store"
+)
+
+func TestAllows(t *testing.T) {
+	const (
+		get      = "/phoenix.v1.ExperimentService/GetExperiment"
+		create   = "/phoenix.v1.ExperimentService/CreateExperiment"
+		export   = "/phoenix.v1.TemplateService/ExportTemplates"
+		imports  = "/phoenix.v1.TemplateService/ImportTemplates"
+		listKeys = "/phoenix.v1.APIKeyService/ListAPIKeys"
+		makeKey  = "/phoenix.v1.APIKeyService/CreateAPIKey"
+		audit    = "/phoenix.v1.AuditService/ListAuditEntries"
+	)
+
+	tests := []struct {
+		name   string
+		scopes []string
+		method string
+		want   bool
+	}{
+		{"read can get", []string{ScopeRead}, get, true},
+		{"read cannot create", []string{ScopeRead}, create, false},
+		{"read can export templates", []string{ScopeRead}, export, true},
+		{"read cannot import templates", []string{ScopeRead}, imports, false},
+		{"read cannot list keys", []string{ScopeRead}, listKeys, false},
+		{"read cannot read the audit log", []string{ScopeRead}, audit, false},
+		{"write can get", []string{ScopeWrite}, get, true},
+		{"write can create", []string{ScopeWrite}, create, true},
+		{"write cannot list keys", []string{ScopeWrite}, listKeys, false},
+		{"write cannot create keys", []string{ScopeWrite}, makeKey, false},
+		{"write cannot read the audit log", []string{ScopeWrite}, audit, false},
+		{"admin can create", []string{ScopeAdmin}, create, true},
+		{"admin can create keys", []string{ScopeAdmin}, makeKey, true},
+		{"admin can read the audit log", []string{ScopeAdmin}, audit, true},
+		{"read and write can create", []string{ScopeRead, ScopeWrite}, create, true},
+		{"unknown scope allows nothing", []string{"owner"}, get, false},
+		{"no scopes allow nothing", nil, get, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allows(tt.scopes, tt.method); got != tt.want {
+				t.Errorf("allows(%v, %s) = %v, want %v", tt.scopes, tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	token, prefix, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(token, tokenPrefix+prefix+"_") {
+		t.Errorf("token %q does not start with %q", token, tokenPrefix+prefix+"_")
+	}
+	if got, ok := parse(token); !ok || got != prefix {
+		t.Errorf("parse(token) = %q, %v, want %q, true", got, ok, prefix)
+	}
+	if hash != Hash(token) {
+		t.Errorf("returned hash %s differs from Hash(token) %s", hash, Hash(token))
+	}
+	if strings.Contains(hash, prefix) || strings.Contains(token, hash) {
+		t.Error("hash must not reveal the token")
+	}
+
+	other, otherPrefix, otherHash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == token || otherPrefix == prefix || otherHash == hash {
+		t.Error("two generated keys share a token, prefix or hash")
+	}
+}
+
+func TestHash(t *testing.T) {
+	sum := sha256.Sum256([]byte("phx_abc_def"))
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"phx_abc_def", hex.EncodeToString(sum[:])},
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	for _, tt := range tests {
+		if got := Hash(tt.token); got != tt.want {
+			t.Errorf("Hash(%q) = %s, want %s", tt.token, got, tt.want)
+		}
+	}
+	if Hash("phx_abc_def") == Hash("phx_abc_deg") {
+		t.Error("different tokens hash the same")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		token  string
+		prefix string
+		ok     bool
+	}{
+		{"phx_3f9c2a1b7d4e_secret", "3f9c2a1b7d4e", true},
+		{"phx_3f9c2a1b7d4e_", "", false},
+		{"phx__secret", "", false},
+		{"phx_3f9c2a1b7d4e", "", false},
+		{"eyJhbGciOiJIUzI1NiJ9.e30.sig", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		prefix, ok := parse(tt.token)
+		if prefix != tt.prefix || ok != tt.ok {
+			t.Errorf("parse(%q) = %q, %v, want %q, %v", tt.token, prefix, ok, tt.prefix, tt.ok)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	token, prefix, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := fakeKeyStore{prefix: {ID: "key-1", Tenant: "payments", Name: "ci", Prefix: prefix, Hash: hash, Scopes: []string{ScopeWrite}}}
+	a := NewAuthenticator(keys, zap.NewNop())
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		code   codes.Code
+	}{
+		{"valid", token, "/phoenix.v1.ExperimentService/CreateExperiment", codes.OK},
+		{"wrong secret", tokenPrefix + prefix + "_" + strings.Repeat("0", 64), "/phoenix.v1.ExperimentService/GetExperiment", codes.Unauthenticated},
+		{"unknown prefix", "phx_000000000000_secret", "/phoenix.v1.ExperimentService/GetExperiment", codes.Unauthenticated},
+		{"missing scope", token, "/phoenix.v1.APIKeyService/CreateAPIKey", codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := a.authenticate(context.Background(), tt.token, tt.method)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %s, want %s (%v)", code, tt.code, err)
+			}
+			if err != nil {
+				return
+			}
+			if user, _ := ctx.Value("user").(string); user != "payments/ci" {
+				t.Errorf("user = %q, want payments/ci", user)
+			}
+			claims, _ := ctx.Value("claims").(map[string]interface{})
+			if claims["api_key_id"] != "key-1" {
+				t.Errorf("api_key_id claim = %v, want key-1", claims["api_key_id"])
+			}
+			if roles, _ := claims["roles"].([]string); len(roles) != 0 {
+				t.Errorf("write key was given roles %v", roles)
+			}
+		})
+	}
+}
+
+// fakeKeyStore serves keys by prefix
+type fakeKeyStore map[string]*store.APIKey
+
+func (f fakeKeyStore) CreateAPIKey(ctx context.Context, key *store.APIKey) error {
+	f[key.Prefix] = key
+	return nil
+}
+
+func (f fakeKeyStore) UseAPIKey(ctx context.Context, prefix string) (*store.APIKey, error) {
+	key, ok := f[prefix]
+	if !ok {
+		return nil, store.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (f fakeKeyStore) GetAPIKey(ctx context.Context, id string) (*store.APIKey, error) {
+	for _, key := range f {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return nil, store.ErrAPIKeyNotFound
+}
+
+func (f fakeKeyStore) ListAPIKeys(ctx context.Context, tenant string) ([]*store.APIKey, error) {
+	var keys []*store.APIKey
+	for _, key := range f {
+		if tenant == "" || key.Tenant == tenant {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (f fakeKeyStore) RevokeAPIKey(ctx context.Context, id string) error {
+	for prefix, key := range f {
+		if key.ID == id {
+			delete(f, prefix)
+			return nil
+		}
+	}
+	return store.ErrAPIKeyNotFound
+}
@@ -0,0 +1,197 @@
+// Package audit records every mutating platform API call: who made it, what
+// it changed, when, and from where. Calls rejected by authentication are
+// recorded whether they mutate or not.
+package audit
+
+import (
+	"context"
+	"net"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/phoenix/platform/pkg/store"
+)
+
+// Actor types recorded with each entry
+const (
+	ActorUser   = "user"
+	ActorAPIKey = "api_key"
+	// ActorAnonymous marks calls rejected before the caller was identified
+	ActorAnonymous = "anonymous"
+)
+
+// readOnlyPrefixes are the RPC name prefixes that never change state
+var readOnlyPrefixes = []string{"Get", "List", "Stream", "Compare", "Watch", "Check", "Export"}
+
+// resourceFields are checked in order to find the ID of the resource a call
+// acted on, first in the request and then in the response
+var resourceFields = []protoreflect.Name{"experiment_id", "agent_id", "key_id", "id"}
+
+// IsMutating reports whether a gRPC method, e.g.
+// /phoenix.v1.ExperimentService/CreateExperiment, may change state. API key
+// scopes use the same classification.
+func IsMutating(fullMethod string) bool {
+	name := path.Base(fullMethod)
+	for _, prefix := range readOnlyPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Recorder writes audit entries for mutating calls
+type Recorder struct {
+	store  store.AuditStore
+	skip   map[string]bool
+	logger *zap.Logger
+}
+
+// New creates a recorder. Calls to skipMethods, such as high-volume agent
+// heartbeats, are not recorded.
+func New(auditStore store.AuditStore, logger *zap.Logger, skipMethods ...string) *Recorder {
+	skip := make(map[string]bool, len(skipMethods))
+	for _, method := range skipMethods {
+		skip[method] = true
+	}
+	return &Recorder{store: auditStore, skip: skip, logger: logger}
+}
+
+// UnaryInterceptor records mutating calls after they complete. It must run
+// after authentication so the caller is known. Streaming RPCs are read-only
+// and are not audited.
+func (r *Recorder) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if IsMutating(info.FullMethod) && !r.skip[info.FullMethod] {
+			r.record(ctx, info.FullMethod, req, resp, err)
+		}
+		return resp, err
+	}
+}
+
+// AuthInterceptor wraps the authentication interceptor and records the calls
+// it rejects, which never reach UnaryInterceptor
+func (r *Recorder) AuthInterceptor(authenticate grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authenticated := false
+		resp, err := authenticate(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			authenticated = true
+			return handler(ctx, req)
+		})
+		if !authenticated && err != nil && !r.skip[info.FullMethod] {
+			r.record(ctx, info.FullMethod, req, nil, err)
+		}
+		return resp, err
+	}
+}
+
+// StreamAuthInterceptor records the streams the authentication interceptor
+// rejects
+func (r *Recorder) StreamAuthInterceptor(authenticate grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		authenticated := false
+		err := authenticate(srv, ss, info, func(srv interface{}, ss grpc.ServerStream) error {
+			authenticated = true
+			return handler(srv, ss)
+		})
+		if !authenticated && err != nil && !r.skip[info.FullMethod] {
+			r.record(ss.Context(), info.FullMethod, nil, nil, err)
+		}
+		return err
+	}
+}
+
+func (r *Recorder) record(ctx context.Context, method string, req, resp interface{}, callErr error) {
+	actor, actorType, tenant := caller(ctx)
+	entry := &store.AuditEntry{
+		Actor:      actor,
+		ActorType:  actorType,
+		Tenant:     tenant,
+		Method:     method,
+		Resource:   resource(req, resp),
+		RemoteAddr: remoteAddr(ctx),
+		Code:       status.Code(callErr).String(),
+	}
+	if callErr != nil {
+		entry.Error = status.Convert(callErr).Message()
+	}
+
+	// The entry is written even if the caller has gone away
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := r.store.RecordAuditEntry(writeCtx, entry); err != nil {
+		r.logger.Error("failed to record audit entry",
+			zap.String("method", method),
+			zap.String("actor", actor),
+			zap.Error(err))
+	}
+}
+
+// caller identifies the authenticated principal of a call
+func caller(ctx context.Context) (actor, actorType, tenant string) {
+	if claims, ok := ctx.Value("claims").(map[string]interface{}); ok {
+		if keyID, ok := claims["api_key_id"].(string); ok {
+			tenant, _ = claims["tenant"].(string)
+			return keyID, ActorAPIKey, tenant
+		}
+	}
+	user, _ := ctx.Value("user").(string)
+	if user == "" {
+		return "", ActorAnonymous, ""
+	}
+	return user, ActorUser, ""
+}
+
+// remoteAddr is the client address, taken from the forwarding header for
+// calls proxied by the in-process REST gateway. The gateway appends the
+// request's RemoteAddr, already resolved by ratelimit.RealIP, as the last
+// hop; earlier hops are whatever the client sent and are not trusted.
+func remoteAddr(ctx context.Context) string {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+	}
+
+	if ip := net.ParseIP(addr); ip != nil && ip.IsLoopback() {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+			hops := strings.Split(forwarded[len(forwarded)-1], ",")
+			return strings.TrimSpace(hops[len(hops)-1])
+		}
+	}
+	return addr
+}
+
+func resource(req, resp interface{}) string {
+	for _, m := range []interface{}{req, resp} {
+		msg, ok := m.(proto.Message)
+		if !ok || msg == nil || !msg.ProtoReflect().IsValid() {
+			continue
+		}
+		reflected := msg.ProtoReflect()
+		fields := reflected.Descriptor().Fields()
+		for _, name := range resourceFields {
+			fd := fields.ByName(name)
+			if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+				continue
+			}
+			if id := reflected.Get(fd).String(); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
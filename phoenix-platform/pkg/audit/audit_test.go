@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/phoenix/platform/pkg/ratelimit"
+	"github.com/phoenix/platform/pkg/store"
+)
+
+func TestIsMutating(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"/phoenix.v1.ExperimentService/GetExperiment", false},
+		{"/phoenix.v1.ExperimentService/ListExperiments", false},
+		{"/phoenix.v1.TemplateService/ExportTemplates", false},
+		{"/phoenix.v1.ExperimentService/CreateExperiment", true},
+		{"/phoenix.v1.TemplateService/ImportTemplates", true},
+		{"/phoenix.v1.APIKeyService/RevokeAPIKey", true},
+	}
+	for _, tt := range tests {
+		if got := IsMutating(tt.method); got != tt.want {
+			t.Errorf("IsMutating(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestAuthInterceptorRecordsRejections(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		authErr error
+		want    []string
+	}{
+		{"rejected read", "/phoenix.v1.ExperimentService/GetExperiment", status.Error(codes.Unauthenticated, "invalid token"), []string{codes.Unauthenticated.String()}},
+		{"rejected write", "/phoenix.v1.ExperimentService/CreateExperiment", status.Error(codes.PermissionDenied, "missing scope"), []string{codes.PermissionDenied.String()}},
+		{"authenticated read", "/phoenix.v1.ExperimentService/GetExperiment", nil, nil},
+		{"skipped method", "/phoenix.v1.AgentService/ReportAgentStatus", status.Error(codes.Unauthenticated, "invalid token"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := &fakeAuditStore{}
+			r := New(entries, zap.NewNop(), "/phoenix.v1.AgentService/ReportAgentStatus")
+
+			authenticate := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				if tt.authErr != nil {
+					return nil, tt.authErr
+				}
+				return handler(ctx, req)
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "ok", nil
+			}
+
+			_, err := r.AuthInterceptor(authenticate)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != status.Code(tt.authErr) {
+				t.Fatalf("err = %v, want %v", err, tt.authErr)
+			}
+
+			var got []string
+			for _, e := range entries.entries {
+				got = append(got, e.Code)
+				if e.ActorType != ActorAnonymous || e.Method != tt.method {
+					t.Errorf("entry = %+v, want an anonymous entry for %s", e, tt.method)
+				}
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("recorded %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteAddr(t *testing.T) {
+	gateway := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 50000}
+	client := &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 50000}
+
+	tests := []struct {
+		name      string
+		peer      net.Addr
+		forwarded []string
+		want      string
+	}{
+		{"direct grpc call", client, nil, "203.0.113.7"},
+		{"direct call cannot spoof", client, []string{"198.51.100.1"}, "203.0.113.7"},
+		{"gateway call", gateway, []string{"198.51.100.1"}, "198.51.100.1"},
+		{"hops sent by the client are skipped", gateway, []string{"192.0.2.99, 198.51.100.1"}, "198.51.100.1"},
+		{"gateway call without header", gateway, nil, "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tt.peer})
+			ctx = metadata.NewIncomingContext(ctx, metadata.MD{"x-forwarded-for": tt.forwarded})
+			if got := remoteAddr(ctx); got != tt.want {
+				t.Errorf("remoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRemoteAddrThroughGateway runs a request through ratelimit.RealIP and
+// the gateway's metadata annotation, as cmd/api wires them
+func TestRemoteAddrThroughGateway(t *testing.T) {
+	realIP, err := ratelimit.RealIP([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := runtime.NewServeMux()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/experiments", nil)
+	req.RemoteAddr = "10.0.0.5:4321"
+	req.Header.Set("X-Forwarded-For", "192.0.2.99, 198.51.100.1")
+
+	var got string
+	realIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, "/phoenix.v1.ExperimentService/ListExperiments")
+		if err != nil {
+			t.Fatal(err)
+		}
+		md, _ := metadata.FromOutgoingContext(ctx)
+		ctx = metadata.NewIncomingContext(context.Background(), md)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 50000}})
+		got = remoteAddr(ctx)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "198.51.100.1" {
+		t.Errorf("remoteAddr = %q, want the address resolved by RealIP", got)
+	}
+}
+
+type fakeAuditStore struct {
+	entries []*store.AuditEntry
+}
+
+func (f *fakeAuditStore) RecordAuditEntry(ctx context.Context, entry *store.AuditEntry) error {
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func (f *fakeAuditStore) ListAuditEntries(ctx context.Context, filter store.AuditFilter) ([]*store.AuditEntry, int, error) {
+	return f.entries, len(f.entries), nil
+}
@@ -0,0 +1,163 @@
+// Package bundle signs and verifies the experiment template bundles Phoenix
+// instances exchange. Bundles are signed with Ed25519 together with their
+// expiry; an importing instance only accepts unexpired bundles signed by a
+// key it trusts.
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+var (
+	// ErrUntrustedKey is returned for bundles signed by an unknown key
+	ErrUntrustedKey = errors.New("bundle is signed by an untrusted key")
+	// ErrExpired is returned for bundles past their expiry
+	ErrExpired = errors.New("bundle has expired")
+)
+
+// signaturePrefix separates bundle signatures from anything else the key
+// might sign
+const signaturePrefix = "phoenix-template-bundle\x00"
+
+// signedMessage binds the expiry, at second precision, to the payload so it
+// cannot be extended without the signing key
+func signedMessage(payload []byte, expiresAt time.Time) []byte {
+	msg := make([]byte, 0, len(signaturePrefix)+8+len(payload))
+	msg = append(msg, signaturePrefix...)
+	msg = binary.BigEndian.AppendUint64(msg, uint64(expiresAt.Unix()))
+	return append(msg, payload...)
+}
+
+// KeyID identifies a public key: the first 8 bytes of its SHA-256, in hex
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Signer signs bundle payloads with this instance's private key
+type Signer struct {
+	key ed25519.PrivateKey
+	id  string
+}
+
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key, id: KeyID(key.Public().(ed25519.PublicKey))}
+}
+
+// LoadSigner reads a PKCS#8 PEM Ed25519 private key, as written by
+// "openssl genpkey -algorithm ed25519"
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: expected a PEM PRIVATE KEY block", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: signing key must be Ed25519", path)
+	}
+	return NewSigner(edKey), nil
+}
+
+// KeyID identifies the signing key in exported bundles
+func (s *Signer) KeyID() string {
+	return s.id
+}
+
+// Public returns the key importing instances need to trust
+func (s *Signer) Public() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Sign returns the signature of payload for a bundle that expires at
+// expiresAt
+func (s *Signer) Sign(payload []byte, expiresAt time.Time) []byte {
+	return ed25519.Sign(s.key, signedMessage(payload, expiresAt))
+}
+
+// Verifier checks bundle signatures against a set of trusted public keys
+type Verifier struct {
+	keys map[string]ed25519.PublicKey
+	now  func() time.Time
+}
+
+func NewVerifier() *Verifier {
+	return &Verifier{keys: map[string]ed25519.PublicKey{}, now: time.Now}
+}
+
+// LoadVerifier trusts every PEM PUBLIC KEY block in the file. An empty path
+// gives a verifier that trusts nothing until Trust is called.
+func LoadVerifier(path string) (*Verifier, error) {
+	v := NewVerifier()
+	if path == "" {
+		return v, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted keys: %w", err)
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s: trusted keys must be Ed25519", path)
+		}
+		v.Trust(edKey)
+	}
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("%s: no PEM PUBLIC KEY blocks found", path)
+	}
+	return v, nil
+}
+
+// Trust accepts bundles signed by pub
+func (v *Verifier) Trust(pub ed25519.PublicKey) {
+	v.keys[KeyID(pub)] = pub
+}
+
+// Verify checks that signature is a valid signature of payload and
+// expiresAt by the trusted key keyID, and that the bundle has not expired
+func (v *Verifier) Verify(payload, signature []byte, keyID string, expiresAt time.Time) error {
+	pub, ok := v.keys[keyID]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUntrustedKey, keyID)
+	}
+	if expiresAt.IsZero() {
+		return errors.New("bundle has no expiry")
+	}
+	if !ed25519.Verify(pub, signedMessage(payload, expiresAt), signature) {
+		return errors.New("bundle signature is invalid")
+	}
+	if !v.now().Before(expiresAt) {
+		return fmt.Errorf("%w at %s", ErrExpired, expiresAt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
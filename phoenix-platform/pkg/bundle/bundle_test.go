@@ -0,0 +1,201 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	signer := newTestSigner(t)
+	other := newTestSigner(t)
+
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(24 * time.Hour)
+	payload := []byte(`{"formatVersion":1,"templates":[{"namespace":"team","name":"topk"}]}`)
+	signature := signer.Sign(payload, expiresAt)
+
+	tampered := append([]byte{}, payload...)
+	tampered[len(tampered)-3] = 'X'
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature []byte
+		keyID     string
+		expiresAt time.Time
+		now       time.Time
+		wantErr   string
+		is        error
+	}{
+		{
+			name:      "valid",
+			payload:   payload,
+			signature: signature,
+			keyID:     signer.KeyID(),
+			expiresAt: expiresAt,
+			now:       now,
+		},
+		{
+			name:      "tampered payload",
+			payload:   tampered,
+			signature: signature,
+			keyID:     signer.KeyID(),
+			expiresAt: expiresAt,
+			now:       now,
+			wantErr:   "signature is invalid",
+		},
+		{
+			name:      "extended expiry",
+			payload:   payload,
+			signature: signature,
+			keyID:     signer.KeyID(),
+			expiresAt: expiresAt.Add(365 * 24 * time.Hour),
+			now:       now,
+			wantErr:   "signature is invalid",
+		},
+		{
+			name:      "untrusted key",
+			payload:   payload,
+			signature: other.Sign(payload, expiresAt),
+			keyID:     other.KeyID(),
+			expiresAt: expiresAt,
+			now:       now,
+			is:        ErrUntrustedKey,
+		},
+		{
+			name:      "signed by another key under a trusted key id",
+			payload:   payload,
+			signature: other.Sign(payload, expiresAt),
+			keyID:     signer.KeyID(),
+			expiresAt: expiresAt,
+			now:       now,
+			wantErr:   "signature is invalid",
+		},
+		{
+			name:      "expired",
+			payload:   payload,
+			signature: signature,
+			keyID:     signer.KeyID(),
+			expiresAt: expiresAt,
+			now:       expiresAt.Add(time.Second),
+			is:        ErrExpired,
+		},
+		{
+			name:      "expires exactly now",
+			payload:   payload,
+			signature: signature,
+			keyID:     signer.KeyID(),
+			expiresAt: expiresAt,
+			now:       expiresAt,
+			is:        ErrExpired,
+		},
+		{
+			name:      "no expiry",
+			payload:   payload,
+			signature: signer.Sign(payload, time.Time{}),
+			keyID:     signer.KeyID(),
+			now:       now,
+			wantErr:   "no expiry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			v.Trust(signer.Public())
+			v.now = func() time.Time { return tt.now }
+
+			err := v.Verify(tt.payload, tt.signature, tt.keyID, tt.expiresAt)
+			switch {
+			case tt.is != nil:
+				if !errors.Is(err, tt.is) {
+					t.Errorf("err = %v, want %v", err, tt.is)
+				}
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Errorf("err = %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := writePEM(t, dir, "templates.key", "PRIVATE KEY", privDER)
+
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trustedFile := writePEM(t, dir, "trusted.pub", "PUBLIC KEY", pubDER)
+
+	signer, err := LoadSigner(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.KeyID() != KeyID(pub) {
+		t.Errorf("signer key id = %s, want %s", signer.KeyID(), KeyID(pub))
+	}
+
+	verifier, err := LoadVerifier(trustedFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := time.Now().Add(time.Hour)
+	if err := verifier.Verify([]byte("payload"), signer.Sign([]byte("payload"), expiresAt), signer.KeyID(), expiresAt); err != nil {
+		t.Errorf("bundle signed with the loaded key was rejected: %v", err)
+	}
+
+	empty, err := LoadVerifier("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := empty.Verify([]byte("payload"), signer.Sign([]byte("payload"), expiresAt), signer.KeyID(), expiresAt); !errors.Is(err, ErrUntrustedKey) {
+		t.Errorf("verifier without keys: err = %v, want %v", err, ErrUntrustedKey)
+	}
+
+	if _, err := LoadSigner(trustedFile); err == nil {
+		t.Error("LoadSigner accepted a public key")
+	}
+	if _, err := LoadVerifier(keyFile); err == nil {
+		t.Error("LoadVerifier accepted a file without public keys")
+	}
+}
+
+func newTestSigner(t *testing.T) *Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSigner(priv)
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,261 @@
+// Package client is a typed Go client for the Phoenix platform API.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+)
+
+// Client wraps the platform API gRPC clients
+type Client struct {
+	conn        *grpc.ClientConn
+	experiments pb.ExperimentServiceClient
+	agents      pb.AgentServiceClient
+	health      healthpb.HealthClient
+}
+
+type options struct {
+	token     string
+	tlsConfig *tls.Config
+	insecure  bool
+	dialOpts  []grpc.DialOption
+}
+
+// Option configures a Client
+type Option func(*options)
+
+// WithToken authenticates every call with the given bearer token
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithTLS dials the API over TLS using the given configuration
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = config
+	}
+}
+
+// WithInsecure dials the API without transport security (local development)
+func WithInsecure() Option {
+	return func(o *options) {
+		o.insecure = true
+	}
+}
+
+// WithDialOptions appends raw gRPC dial options
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOpts = append(o.dialOpts, opts...)
+	}
+}
+
+// New connects to the platform API gRPC endpoint, e.g. "phoenix-api:5050"
+func New(target string, opts ...Option) (*Client, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	dialOpts := append([]grpc.DialOption{}, o.dialOpts...)
+	if o.insecure {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		tlsConfig := o.tlsConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+	if o.token != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(bearerToken{token: o.token, secure: !o.insecure}))
+	}
+
+	conn, err := grpc.Dial(target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", target, err)
+	}
+
+	return &Client{
+		conn:        conn,
+		experiments: pb.NewExperimentServiceClient(conn),
+		agents:      pb.NewAgentServiceClient(conn),
+		health:      healthpb.NewHealthClient(conn),
+	}, nil
+}
+
+// Close releases the underlying connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Experiments exposes the raw generated client for calls not wrapped here
+func (c *Client) Experiments() pb.ExperimentServiceClient {
+	return c.experiments
+}
+
+// CreateExperiment creates an experiment and returns its ID
+func (c *Client) CreateExperiment(ctx context.Context, spec *pb.ExperimentSpec) (string, error) {
+	resp, err := c.experiments.CreateExperiment(ctx, &pb.CreateExperimentRequest{Spec: spec})
+	if err != nil {
+		return "", err
+	}
+	return resp.ExperimentId, nil
+}
+
+func (c *Client) GetExperiment(ctx context.Context, id string) (*pb.Experiment, error) {
+	return c.experiments.GetExperiment(ctx, &pb.GetExperimentRequest{ExperimentId: id})
+}
+
+// ListOptions filters and paginates ListExperiments
+type ListOptions struct {
+	Owner  string
+	Status string
+	Limit  int32
+	Offset int32
+}
+
+// ListExperiments returns one page of experiments and the total count
+func (c *Client) ListExperiments(ctx context.Context, opts ListOptions) ([]*pb.Experiment, int32, error) {
+	resp, err := c.experiments.ListExperiments(ctx, &pb.ListExperimentsRequest{
+		Owner:  opts.Owner,
+		Status: opts.Status,
+		Limit:  opts.Limit,
+		Offset: opts.Offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Experiments, resp.Total, nil
+}
+
+func (c *Client) UpdateExperiment(ctx context.Context, id string, spec *pb.ExperimentSpec) (*pb.Experiment, error) {
+	return c.experiments.UpdateExperiment(ctx, &pb.UpdateExperimentRequest{ExperimentId: id, Spec: spec})
+}
+
+func (c *Client) DeleteExperiment(ctx context.Context, id string) error {
+	_, err := c.experiments.DeleteExperiment(ctx, &pb.DeleteExperimentRequest{ExperimentId: id})
+	return err
+}
+
+func (c *Client) GetExperimentStatus(ctx context.Context, id string) (*pb.ExperimentStatus, error) {
+	return c.experiments.GetExperimentStatus(ctx, &pb.GetExperimentStatusRequest{ExperimentId: id})
+}
+
+// WatchExperiment streams updates for an experiment and calls fn for each one
+// until the stream ends, fn returns an error, or ctx is cancelled.
+func (c *Client) WatchExperiment(ctx context.Context, id string, fn func(*pb.ExperimentUpdate) error) error {
+	stream, err := c.experiments.StreamExperimentUpdates(ctx, &pb.StreamExperimentUpdatesRequest{ExperimentId: id})
+	if err != nil {
+		return err
+	}
+
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(update); err != nil {
+			return err
+		}
+	}
+}
+
+// PromoteVariant promotes the named variant of a completed experiment
+func (c *Client) PromoteVariant(ctx context.Context, id, variant string) (string, error) {
+	resp, err := c.experiments.PromoteVariant(ctx, &pb.PromoteVariantRequest{ExperimentId: id, Variant: variant})
+	if err != nil {
+		return "", err
+	}
+	return resp.Message, nil
+}
+
+// GetExperimentArtifacts returns the stored artifacts, optionally for one variant
+func (c *Client) GetExperimentArtifacts(ctx context.Context, id, variant string) ([]*pb.Artifact, error) {
+	resp, err := c.experiments.GetExperimentArtifacts(ctx, &pb.GetExperimentArtifactsRequest{ExperimentId: id, Variant: variant})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Artifacts, nil
+}
+
+// CompareExperiments diffs the specs and KPIs of two experiments
+func (c *Client) CompareExperiments(ctx context.Context, id, otherID string) (*pb.CompareExperimentsResponse, error) {
+	return c.experiments.CompareExperiments(ctx, &pb.CompareExperimentsRequest{ExperimentId: id, OtherExperimentId: otherID})
+}
+
+// ListExperimentSpecVersions returns every revision of an experiment's spec, oldest first
+func (c *Client) ListExperimentSpecVersions(ctx context.Context, id string) ([]*pb.ExperimentSpecVersion, error) {
+	resp, err := c.experiments.ListExperimentSpecVersions(ctx, &pb.ListExperimentSpecVersionsRequest{ExperimentId: id})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Versions, nil
+}
+
+// ProposeExperiment creates a draft experiment that needs approval
+func (c *Client) ProposeExperiment(ctx context.Context, req *pb.ProposeExperimentRequest) (string, error) {
+	resp, err := c.experiments.ProposeExperiment(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.ExperimentId, nil
+}
+
+// ApproveExperiment approves a draft experiment so it starts running
+func (c *Client) ApproveExperiment(ctx context.Context, id, comment string) (*pb.Experiment, error) {
+	return c.experiments.ApproveExperiment(ctx, &pb.ReviewExperimentRequest{ExperimentId: id, Comment: comment})
+}
+
+// RejectExperiment rejects a draft experiment
+func (c *Client) RejectExperiment(ctx context.Context, id, comment string) (*pb.Experiment, error) {
+	return c.experiments.RejectExperiment(ctx, &pb.ReviewExperimentRequest{ExperimentId: id, Comment: comment})
+}
+
+// ReportAgentStatus sends a collector heartbeat
+func (c *Client) ReportAgentStatus(ctx context.Context, req *pb.ReportAgentStatusRequest) (*pb.Agent, error) {
+	return c.agents.ReportAgentStatus(ctx, req)
+}
+
+// ListAgents returns the collector inventory matching the request filters
+func (c *Client) ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error) {
+	return c.agents.ListAgents(ctx, req)
+}
+
+// Health asks the grpc.health.v1 service for the status of one dependency
+// of the API, e.g. "store"; the empty name is the overall status
+func (c *Client) Health(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	resp, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+	return resp.Status, nil
+}
+
+// bearerToken attaches an Authorization header to every RPC
+type bearerToken struct {
+	token  string
+	secure bool
+}
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return t.secure
+}
@@ -0,0 +1,323 @@
+// Package config loads service configuration from a YAML file, environment
+// variables and command line flags into a tagged struct.
+//
+// Fields are described with struct tags:
+//
+//	type Config struct {
+//		GRPCPort int           `yaml:"grpc_port" env:"GRPC_PORT" flag:"grpc-port" usage:"gRPC listen port"`
+//		Timeout  time.Duration `yaml:"timeout" env:"TIMEOUT"`
+//		DBURL    string        `yaml:"database_url" env:"DATABASE_URL" required:"true"`
+//	}
+//
+// Values already in the struct are the defaults. The file overrides them,
+// environment variables override the file and flags override everything.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError lists every invalid or missing key found while loading
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Validator is implemented by configs with checks beyond required fields.
+// Its problems are reported together with the loader's own.
+type Validator interface {
+	Validate() []string
+}
+
+type options struct {
+	file     string
+	fileFlag string
+	args     []string
+	rest     *[]string
+	name     string
+	lookup   func(string) (string, bool)
+}
+
+// Option configures Load
+type Option func(*options)
+
+// WithFile reads the given YAML file. An empty path is ignored.
+func WithFile(path string) Option {
+	return func(o *options) {
+		o.file = path
+	}
+}
+
+// WithArgs parses the given command line arguments as flags. A -config flag
+// naming the YAML file is always available.
+func WithArgs(name string, args []string) Option {
+	return func(o *options) {
+		o.name = name
+		o.args = args
+	}
+}
+
+// WithRemainingArgs stores the arguments left after the flags, e.g. the
+// action of a subcommand
+func WithRemainingArgs(rest *[]string) Option {
+	return func(o *options) {
+		o.rest = rest
+	}
+}
+
+// WithLookup replaces os.LookupEnv, e.g. to load from a fixed environment
+func WithLookup(lookup func(string) (string, bool)) Option {
+	return func(o *options) {
+		o.lookup = lookup
+	}
+}
+
+// Load populates dst, a pointer to a tagged struct, from every source
+func Load(dst interface{}, opts ...Option) error {
+	o := &options{lookup: os.LookupEnv}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: destination must be a pointer to a struct, got %T", dst)
+	}
+
+	var fields []field
+	collect(v.Elem(), "", &fields)
+
+	var problems []string
+
+	// Flags are parsed first so -config can name the file, but applied last
+	flagValues := map[string]string{}
+	if o.args != nil {
+		fs := flag.NewFlagSet(o.name, flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		configFile := fs.String("config", o.file, "path to a YAML configuration file")
+		for _, f := range fields {
+			if f.flag != "" {
+				fs.Var(&rawFlag{name: f.flag, values: flagValues, isBool: f.value.Kind() == reflect.Bool}, f.flag, f.usage)
+			}
+		}
+		if err := fs.Parse(o.args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				fs.SetOutput(os.Stderr)
+				fs.PrintDefaults()
+			}
+			return err
+		}
+		o.file = *configFile
+		if o.rest != nil {
+			*o.rest = fs.Args()
+		}
+	}
+
+	if o.file != "" {
+		problems = append(problems, loadFile(o.file, dst)...)
+	}
+
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if raw, ok := o.lookup(f.env); ok && raw != "" {
+			if err := setValue(f.value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", f.env, err))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if raw, ok := flagValues[f.flag]; ok {
+			if err := setValue(f.value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("-%s: %v", f.flag, err))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if f.required && f.value.IsZero() {
+			problems = append(problems, fmt.Sprintf("%s is required", f.describe()))
+		}
+	}
+
+	if validator, ok := dst.(Validator); ok {
+		problems = append(problems, validator.Validate()...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// field is one settable leaf of the config struct
+type field struct {
+	key      string
+	env      string
+	flag     string
+	usage    string
+	required bool
+	value    reflect.Value
+}
+
+func (f field) describe() string {
+	names := []string{f.key}
+	if f.env != "" {
+		names = append(names, f.env)
+	}
+	if f.flag != "" {
+		names = append(names, "-"+f.flag)
+	}
+	return strings.Join(names, " / ")
+}
+
+// collect walks nested structs; time.Duration and slices are leaves
+func collect(v reflect.Value, prefix string, fields *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if key == "" {
+			key = strings.ToLower(sf.Name)
+		}
+		if key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+			collect(fv, key, fields)
+			continue
+		}
+
+		*fields = append(*fields, field{
+			key:      key,
+			env:      sf.Tag.Get("env"),
+			flag:     sf.Tag.Get("flag"),
+			usage:    sf.Tag.Get("usage"),
+			required: sf.Tag.Get("required") == "true",
+			value:    fv,
+		})
+	}
+}
+
+func loadFile(path string, dst interface{}) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to read %s: %v", path, err)}
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(dst)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		problems := make([]string, len(typeErr.Errors))
+		for i, e := range typeErr.Errors {
+			problems[i] = fmt.Sprintf("%s: %s", path, e)
+		}
+		return problems
+	}
+	return []string{fmt.Sprintf("%s: %v", path, err)}
+}
+
+// setValue parses raw into a leaf field
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// rawFlag records flag values so they can be applied after the file and
+// environment
+type rawFlag struct {
+	name   string
+	values map[string]string
+	isBool bool
+}
+
+func (f *rawFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	return f.values[f.name]
+}
+
+// IsBoolFlag lets boolean fields be set with a bare -flag
+func (f *rawFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+func (f *rawFlag) Set(s string) error {
+	f.values[f.name] = s
+	return nil
+}
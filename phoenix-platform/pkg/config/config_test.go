@@ -0,0 +1,231 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Port    int           `yaml:"port" env:"PORT" flag:"port"`
+	Timeout time.Duration `yaml:"timeout" env:"TIMEOUT" flag:"timeout"`
+	Debug   bool          `yaml:"debug" env:"DEBUG" flag:"debug"`
+	Tags    []string      `yaml:"tags" env:"TAGS" flag:"tags"`
+	DB      struct {
+		URL string `yaml:"url" env:"DB_URL" flag:"db-url" required:"true"`
+	} `yaml:"db"`
+}
+
+func defaultTestConfig() testConfig {
+	cfg := testConfig{Port: 1, Timeout: time.Second, Tags: []string{"default"}}
+	cfg.DB.URL = "postgres://default"
+	return cfg
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "port: 2\ntimeout: 2s\ntags: [file]\ndb:\n  url: postgres://file\n")
+
+	tests := []struct {
+		name  string
+		file  string
+		env   map[string]string
+		args  []string
+		port  int
+		url   string
+		tags  []string
+		debug bool
+	}{
+		{
+			name: "defaults",
+			port: 1,
+			url:  "postgres://default",
+			tags: []string{"default"},
+		},
+		{
+			name: "file overrides defaults",
+			file: file,
+			port: 2,
+			url:  "postgres://file",
+			tags: []string{"file"},
+		},
+		{
+			name:  "env overrides file",
+			file:  file,
+			env:   map[string]string{"PORT": "3", "TAGS": "a, b,,c", "DEBUG": "true"},
+			port:  3,
+			url:   "postgres://file",
+			tags:  []string{"a", "b", "c"},
+			debug: true,
+		},
+		{
+			name: "flags override env",
+			file: file,
+			env:  map[string]string{"PORT": "3", "DB_URL": "postgres://env", "DEBUG": "true"},
+			args: []string{"-port", "4", "-db-url=postgres://flag", "-debug=false"},
+			port: 4,
+			url:  "postgres://flag",
+			tags: []string{"file"},
+		},
+		{
+			name: "empty env values are ignored",
+			file: file,
+			env:  map[string]string{"PORT": ""},
+			port: 2,
+			url:  "postgres://file",
+			tags: []string{"file"},
+		},
+		{
+			name: "config flag names the file",
+			args: []string{"-config", file},
+			port: 2,
+			url:  "postgres://file",
+			tags: []string{"file"},
+		},
+		{
+			name:  "bare boolean flag",
+			args:  []string{"-debug"},
+			port:  1,
+			url:   "postgres://default",
+			tags:  []string{"default"},
+			debug: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultTestConfig()
+			opts := []Option{WithFile(tt.file), WithLookup(lookup(tt.env))}
+			if tt.args != nil {
+				opts = append(opts, WithArgs("test", tt.args))
+			}
+			if err := Load(&cfg, opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.Port != tt.port || cfg.DB.URL != tt.url || cfg.Debug != tt.debug || !reflect.DeepEqual(cfg.Tags, tt.tags) {
+				t.Errorf("got port=%d url=%s debug=%v tags=%v, want port=%d url=%s debug=%v tags=%v",
+					cfg.Port, cfg.DB.URL, cfg.Debug, cfg.Tags, tt.port, tt.url, tt.debug, tt.tags)
+			}
+		})
+	}
+}
+
+func TestLoadProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		want []string
+	}{
+		{
+			name: "unknown key",
+			file: "port: 2\nprot: 3\n",
+			want: []string{"field prot not found"},
+		},
+		{
+			name: "unknown nested key",
+			file: "db:\n  uri: postgres://typo\n",
+			want: []string{"field uri not found"},
+		},
+		{
+			name: "wrong type in file",
+			file: "port: many\n",
+			want: []string{"cannot unmarshal"},
+		},
+		{
+			name: "invalid env values are all reported",
+			env:  map[string]string{"PORT": "x", "TIMEOUT": "soon"},
+			want: []string{`PORT: invalid integer "x"`, `TIMEOUT: invalid duration "soon"`},
+		},
+		{
+			name: "invalid flag value",
+			args: []string{"-debug=maybe"},
+			want: []string{`-debug: invalid boolean "maybe"`},
+		},
+		{
+			name: "required field",
+			args: []string{"-db-url="},
+			want: []string{"db.url / DB_URL / -db-url is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultTestConfig()
+			opts := []Option{WithLookup(lookup(tt.env))}
+			if tt.file != "" {
+				opts = append(opts, WithFile(writeFile(t, tt.file)))
+			}
+			if tt.args != nil {
+				opts = append(opts, WithArgs("test", tt.args))
+			}
+
+			err := Load(&cfg, opts...)
+			if err == nil {
+				t.Fatal("Load succeeded")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadUnknownFlag(t *testing.T) {
+	cfg := defaultTestConfig()
+	if err := Load(&cfg, WithArgs("test", []string{"-prot", "3"}), WithLookup(lookup(nil))); err == nil {
+		t.Error("unknown flag was accepted")
+	}
+}
+
+func TestLoadRemainingArgs(t *testing.T) {
+	cfg := defaultTestConfig()
+	var rest []string
+	if err := Load(&cfg, WithArgs("test", []string{"-port", "4", "status"}), WithRemainingArgs(&rest), WithLookup(lookup(nil))); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 4 || !reflect.DeepEqual(rest, []string{"status"}) {
+		t.Errorf("port = %d, rest = %v", cfg.Port, rest)
+	}
+}
+
+type validatedConfig struct {
+	Port int `yaml:"port" env:"PORT"`
+}
+
+func (c *validatedConfig) Validate() []string {
+	if c.Port > 65535 {
+		return []string{"port: out of range"}
+	}
+	return nil
+}
+
+func TestLoadValidator(t *testing.T) {
+	cfg := validatedConfig{}
+	err := Load(&cfg, WithLookup(lookup(map[string]string{"PORT": "70000"})))
+	if err == nil || !strings.Contains(err.Error(), "port: out of range") {
+		t.Errorf("err = %v, want the validator's problem", err)
+	}
+}
+
+func lookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,58 @@
+// Package deploy rolls experiment collector pipelines out to the environment
+// an experiment targets.
+package deploy
+
+import (
+	"context"
+	"fmt"
+)
+
+// Target environments an experiment can select
+const (
+	EnvironmentKubernetes = "kubernetes"
+	EnvironmentVM         = "vm"
+)
+
+// Deployment is one experiment variant to roll out
+type Deployment struct {
+	ExperimentID string
+	Variant      string
+	// Nodes restricts the rollout to these hosts; empty means all nodes
+	// matching NodeSelector
+	Nodes        []string
+	NodeSelector map[string]string
+	// CollectorConfig is the rendered OTel collector configuration
+	CollectorConfig []byte
+	// Manifest is the PhoenixProcessPipeline resource for Kubernetes targets,
+	// already restricted to Nodes and NodeSelector
+	Manifest []byte
+}
+
+// Name is the identifier used for the variant's resources and units
+func (d *Deployment) Name() string {
+	return fmt.Sprintf("%s-%s", d.ExperimentID, d.Variant)
+}
+
+// Backend deploys and removes variant collectors in one kind of environment
+type Backend interface {
+	Name() string
+	Deploy(ctx context.Context, d *Deployment) error
+	Remove(ctx context.Context, d *Deployment) error
+}
+
+// Backends maps target environments to the backend serving them
+type Backends map[string]Backend
+
+// For returns the backend for an environment. An empty environment means
+// Kubernetes, which is where experiments ran before targets existed.
+func (b Backends) For(environment string) (Backend, error) {
+	if environment == "" {
+		environment = EnvironmentKubernetes
+	}
+
+	backend, ok := b[environment]
+	if !ok {
+		return nil, fmt.Errorf("no deployment backend configured for environment %q", environment)
+	}
+	return backend, nil
+}
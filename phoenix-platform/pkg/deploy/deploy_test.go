@@ -0,0 +1,41 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+)
+
+type namedBackend string
+
+func (b namedBackend) Name() string                                    { return string(b) }
+func (b namedBackend) Deploy(ctx context.Context, d *Deployment) error { return nil }
+func (b namedBackend) Remove(ctx context.Context, d *Deployment) error { return nil }
+
+func TestBackendsFor(t *testing.T) {
+	backends := Backends{
+		EnvironmentKubernetes: namedBackend(EnvironmentKubernetes),
+		EnvironmentVM:         namedBackend(EnvironmentVM),
+	}
+
+	tests := []struct {
+		environment string
+		want        string
+	}{
+		{"", EnvironmentKubernetes},
+		{EnvironmentKubernetes, EnvironmentKubernetes},
+		{EnvironmentVM, EnvironmentVM},
+	}
+	for _, tt := range tests {
+		backend, err := backends.For(tt.environment)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.environment, err)
+		}
+		if backend.Name() != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.environment, backend.Name(), tt.want)
+		}
+	}
+
+	if _, err := (Backends{}).For(EnvironmentVM); err == nil {
+		t.Error("unconfigured environment returned a backend")
+	}
+}
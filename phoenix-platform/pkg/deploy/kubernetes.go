@@ -0,0 +1,119 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	fieldOwner = "phoenix-api"
+
+	// collectorConfigKey matches the --config path the pipeline operator
+	// gives the collector
+	collectorConfigKey = "config.yaml"
+)
+
+// KubernetesBackend writes the collector ConfigMap and applies the
+// PhoenixProcessPipeline resource; the pipeline operator turns it into a
+// DaemonSet.
+type KubernetesBackend struct {
+	client    client.Client
+	discovery rest.Interface
+	namespace string
+}
+
+func NewKubernetesBackend(cfg *rest.Config, namespace string) (*KubernetesBackend, error) {
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return &KubernetesBackend{client: c, discovery: dc.RESTClient(), namespace: namespace}, nil
+}
+
+// Ping asks the API server's /readyz endpoint whether it can serve requests
+func (k *KubernetesBackend) Ping(ctx context.Context) error {
+	return k.discovery.Get().AbsPath("/readyz").Do(ctx).Error()
+}
+
+func (k *KubernetesBackend) Name() string {
+	return EnvironmentKubernetes
+}
+
+func (k *KubernetesBackend) Deploy(ctx context.Context, d *Deployment) error {
+	if len(d.CollectorConfig) == 0 {
+		return fmt.Errorf("variant %s has no collector config", d.Variant)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Name() + "-config",
+			Namespace: k.namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, k.client, configMap, func() error {
+		configMap.Labels = labels(d)
+		configMap.Data = map[string]string{collectorConfigKey: string(d.CollectorConfig)}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write collector config: %w", err)
+	}
+
+	pipeline, err := k.pipeline(d)
+	if err != nil {
+		return err
+	}
+	if err := k.client.Patch(ctx, pipeline, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership); err != nil {
+		return fmt.Errorf("failed to apply pipeline: %w", err)
+	}
+	return nil
+}
+
+func (k *KubernetesBackend) Remove(ctx context.Context, d *Deployment) error {
+	pipeline, err := k.pipeline(d)
+	if err != nil {
+		return err
+	}
+	if err := k.client.Delete(ctx, pipeline); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete pipeline: %w", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Name() + "-config",
+			Namespace: k.namespace,
+		},
+	}
+	if err := k.client.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete collector config: %w", err)
+	}
+	return nil
+}
+
+func (k *KubernetesBackend) pipeline(d *Deployment) (*unstructured.Unstructured, error) {
+	pipeline := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(d.Manifest, &pipeline.Object); err != nil {
+		return nil, fmt.Errorf("failed to decode pipeline manifest: %w", err)
+	}
+	pipeline.SetNamespace(k.namespace)
+	return pipeline, nil
+}
+
+func labels(d *Deployment) map[string]string {
+	return map[string]string{
+		"phoenix.io/experiment-id": d.ExperimentID,
+		"phoenix.io/variant":       d.Variant,
+	}
+}
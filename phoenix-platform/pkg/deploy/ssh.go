@@ -0,0 +1,190 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// SSHConfig describes how to reach and manage collectors on plain hosts
+type SSHConfig struct {
+	User    string
+	KeyFile string
+	Port    int
+	// ConfigDir is where collector configs are installed on the host
+	ConfigDir string
+	// Unit is the systemd template unit; the instance is the deployment name
+	Unit string
+}
+
+// SSHBackend deploys to VMs by copying the rendered config over scp and
+// restarting a templated systemd unit, e.g. phoenix-collector@<name>.service.
+// Next to the config it installs <name>.env with the values of the
+// config's placeholders, which the unit loads with EnvironmentFile=.
+// It shells out to the system ssh client so existing ssh-agent and
+// known_hosts setup applies.
+type SSHBackend struct {
+	cfg SSHConfig
+}
+
+func NewSSHBackend(cfg SSHConfig) (*SSHBackend, error) {
+	if cfg.User == "" {
+		return nil, fmt.Errorf("ssh user is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 22
+	}
+	if cfg.ConfigDir == "" {
+		cfg.ConfigDir = "/etc/phoenix"
+	}
+	if cfg.Unit == "" {
+		cfg.Unit = "phoenix-collector@"
+	}
+	return &SSHBackend{cfg: cfg}, nil
+}
+
+func (s *SSHBackend) Name() string {
+	return EnvironmentVM
+}
+
+func (s *SSHBackend) Deploy(ctx context.Context, d *Deployment) error {
+	if len(d.Nodes) == 0 {
+		return fmt.Errorf("vm deployments require target nodes")
+	}
+	if len(d.CollectorConfig) == 0 {
+		return fmt.Errorf("variant %s has no collector config", d.Variant)
+	}
+
+	config, err := writeTemp(d.CollectorConfig)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(config)
+
+	var errs []error
+	for _, node := range d.Nodes {
+		if err := s.deployNode(ctx, d, node, config); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", node, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deployNode stages the config and environment in a private directory made
+// by mktemp -d, owned by the ssh user and unreadable to other users, then
+// installs both and restarts the unit.
+func (s *SSHBackend) deployNode(ctx context.Context, d *Deployment, node, config string) error {
+	env, err := writeTemp(environment(d, node))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(env)
+
+	out, err := s.ssh(ctx, node, "mktemp -d")
+	if err != nil {
+		return err
+	}
+	dir := strings.TrimSpace(string(out))
+	if !path.IsAbs(dir) {
+		return fmt.Errorf("mktemp returned %q", dir)
+	}
+	staged, stagedEnv := path.Join(dir, "config.yaml"), path.Join(dir, "collector.env")
+
+	err = s.scp(ctx, config, node, staged)
+	if err == nil {
+		err = s.scp(ctx, env, node, stagedEnv)
+	}
+	if err != nil {
+		// Best effort; the directory holds nothing other users can read
+		s.ssh(ctx, node, "rm -rf "+dir)
+		return err
+	}
+
+	script := fmt.Sprintf("trap 'rm -rf %s' EXIT; sudo install -D -m 0644 %s %s && sudo install -D -m 0644 %s %s && sudo systemctl restart %s",
+		dir, staged, s.configPath(d), stagedEnv, s.envPath(d), s.unit(d))
+	_, err = s.ssh(ctx, node, script)
+	return err
+}
+
+func (s *SSHBackend) Remove(ctx context.Context, d *Deployment) error {
+	script := fmt.Sprintf("sudo systemctl disable --now %s; sudo rm -f %s %s", s.unit(d), s.configPath(d), s.envPath(d))
+
+	var errs []error
+	for _, node := range d.Nodes {
+		if _, err := s.ssh(ctx, node, script); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", node, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SSHBackend) configPath(d *Deployment) string {
+	return path.Join(s.cfg.ConfigDir, d.Name()+".yaml")
+}
+
+func (s *SSHBackend) envPath(d *Deployment) string {
+	return path.Join(s.cfg.ConfigDir, d.Name()+".env")
+}
+
+func (s *SSHBackend) unit(d *Deployment) string {
+	return s.cfg.Unit + d.Name() + ".service"
+}
+
+func (s *SSHBackend) options() []string {
+	opts := []string{"-o", "BatchMode=yes"}
+	if s.cfg.KeyFile != "" {
+		opts = append(opts, "-i", s.cfg.KeyFile)
+	}
+	return opts
+}
+
+func (s *SSHBackend) scp(ctx context.Context, src, node, dst string) error {
+	args := append(s.options(), "-P", strconv.Itoa(s.cfg.Port), src, fmt.Sprintf("%s@%s:%s", s.cfg.User, node, dst))
+	_, err := run(exec.CommandContext(ctx, "scp", args...))
+	return err
+}
+
+// ssh runs a script on a node and returns its output
+func (s *SSHBackend) ssh(ctx context.Context, node, script string) ([]byte, error) {
+	args := append(s.options(), "-p", strconv.Itoa(s.cfg.Port), fmt.Sprintf("%s@%s", s.cfg.User, node), script)
+	return run(exec.CommandContext(ctx, "ssh", args...))
+}
+
+// environment fills in the placeholders of the pipeline templates for one
+// node
+func environment(d *Deployment, node string) []byte {
+	return []byte(fmt.Sprintf("PHOENIX_EXPERIMENT_ID=%s\nPHOENIX_VARIANT=%s\nNODE_NAME=%s\n", d.ExperimentID, d.Variant, node))
+}
+
+func writeTemp(content []byte) (string, error) {
+	f, err := os.CreateTemp("", "phoenix-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func run(cmd *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", cmd.Path, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, nil
+}
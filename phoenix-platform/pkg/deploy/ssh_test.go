@@ -0,0 +1,146 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeSSH puts ssh and scp scripts first on PATH. Both append their
+// arguments to a log; scp copies the file it is given into the capture
+// directory under the destination's base name, and ssh answers mktemp -d
+// with a fixed staging directory.
+func fakeSSH(t *testing.T, failScp bool) (log, capture string) {
+	t.Helper()
+	bin := t.TempDir()
+	capture = t.TempDir()
+	log = filepath.Join(t.TempDir(), "log")
+
+	scpExit := "0"
+	if failScp {
+		scpExit = "1"
+	}
+	scripts := map[string]string{
+		"ssh": `#!/bin/sh
+echo "ssh $@" >> ` + log + `
+for last; do :; done
+[ "$last" = "mktemp -d" ] && echo /tmp/tmp.staging
+exit 0
+`,
+		"scp": `#!/bin/sh
+echo "scp $@" >> ` + log + `
+for last; do :; done
+src=$(eval echo \${$(($# - 1))})
+cp "$src" "` + capture + `/${last##*/}"
+exit ` + scpExit + `
+`,
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log, capture
+}
+
+func readLog(t *testing.T, log string) []string {
+	t.Helper()
+	content, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func testDeployment() *Deployment {
+	return &Deployment{
+		ExperimentID:    "exp-1",
+		Variant:         "candidate",
+		Nodes:           []string{"host-a"},
+		CollectorConfig: []byte("receivers: {}\n"),
+	}
+}
+
+func TestSSHDeployStagesInPrivateDirectory(t *testing.T) {
+	log, capture := fakeSSH(t, false)
+	backend, err := NewSSHBackend(SSHConfig{User: "deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.Deploy(context.Background(), testDeployment()); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := readLog(t, log)
+	if len(calls) != 4 {
+		t.Fatalf("calls = %q, want mktemp, two copies and the install", calls)
+	}
+	if !strings.HasSuffix(calls[0], "deploy@host-a mktemp -d") {
+		t.Errorf("first call %q does not create the staging directory", calls[0])
+	}
+	for _, c := range calls[1:3] {
+		if !strings.Contains(c, "deploy@host-a:/tmp/tmp.staging/") {
+			t.Errorf("%q does not copy into the staging directory", c)
+		}
+	}
+	install := calls[3]
+	for _, want := range []string{
+		"trap 'rm -rf /tmp/tmp.staging' EXIT",
+		"/tmp/tmp.staging/config.yaml /etc/phoenix/exp-1-candidate.yaml",
+		"/tmp/tmp.staging/collector.env /etc/phoenix/exp-1-candidate.env",
+		"sudo systemctl restart phoenix-collector@exp-1-candidate.service",
+	} {
+		if !strings.Contains(install, want) {
+			t.Errorf("install script %q does not contain %q", install, want)
+		}
+	}
+	if strings.Contains(strings.Join(calls, "\n"), ":/tmp/phoenix-") {
+		t.Error("files are still staged at predictable paths")
+	}
+
+	env, err := os.ReadFile(filepath.Join(capture, "collector.env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "PHOENIX_EXPERIMENT_ID=exp-1\nPHOENIX_VARIANT=candidate\nNODE_NAME=host-a\n"; string(env) != want {
+		t.Errorf("env = %q, want %q", env, want)
+	}
+}
+
+func TestSSHDeployCleansUpFailedCopy(t *testing.T) {
+	log, _ := fakeSSH(t, true)
+	backend, err := NewSSHBackend(SSHConfig{User: "deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = backend.Deploy(context.Background(), testDeployment())
+	if err == nil || !strings.Contains(err.Error(), "host-a") {
+		t.Fatalf("err = %v, want the failing node", err)
+	}
+	calls := readLog(t, log)
+	if last := calls[len(calls)-1]; !strings.HasSuffix(last, "rm -rf /tmp/tmp.staging") {
+		t.Errorf("last call %q does not remove the staging directory", last)
+	}
+	for _, c := range calls {
+		if strings.Contains(c, "systemctl") {
+			t.Errorf("unit was restarted after a failed copy: %q", c)
+		}
+	}
+}
+
+func TestSSHDeployRequiresNodes(t *testing.T) {
+	backend, err := NewSSHBackend(SSHConfig{User: "deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := testDeployment()
+	d.Nodes = nil
+	if err := backend.Deploy(context.Background(), d); err == nil {
+		t.Error("deployment without nodes was accepted")
+	}
+}
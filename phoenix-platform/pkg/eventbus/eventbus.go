@@ -0,0 +1,199 @@
+// Package eventbus carries typed control-plane events between Phoenix
+// services so that new consumers can subscribe without adding webhooks.
+package eventbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Type identifies the kind of an event
+type Type string
+
+const (
+	TypeAnomalyDetected        Type = "anomaly.detected"
+	TypeModeChanged            Type = "mode.changed"
+	TypeExperimentStateChanged Type = "experiment.state_changed"
+	TypeBenchmarkCompleted     Type = "benchmark.completed"
+)
+
+// Event is the envelope sent over the bus
+type Event struct {
+	ID     string          `json:"id"`
+	Type   Type            `json:"type"`
+	Source string          `json:"source"`
+	Time   time.Time       `json:"time"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Payload is implemented by every typed event
+type Payload interface {
+	EventType() Type
+}
+
+// AnomalyDetected is emitted when a detector flags a metric as anomalous
+type AnomalyDetected struct {
+	Detector  string            `json:"detector"`
+	Metric    string            `json:"metric"`
+	Value     float64           `json:"value"`
+	Threshold float64           `json:"threshold"`
+	Severity  string            `json:"severity"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+func (AnomalyDetected) EventType() Type { return TypeAnomalyDetected }
+
+// ModeChanged is emitted when a pipeline switches optimization mode
+type ModeChanged struct {
+	Pipeline     string `json:"pipeline"`
+	Node         string `json:"node,omitempty"`
+	PreviousMode string `json:"previous_mode"`
+	Mode         string `json:"mode"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+func (ModeChanged) EventType() Type { return TypeModeChanged }
+
+// ExperimentStateChanged is emitted on every experiment phase transition
+type ExperimentStateChanged struct {
+	ExperimentID  string `json:"experiment_id"`
+	Name          string `json:"name"`
+	Owner         string `json:"owner"`
+	PreviousPhase string `json:"previous_phase,omitempty"`
+	Phase         string `json:"phase"`
+	Message       string `json:"message,omitempty"`
+}
+
+func (ExperimentStateChanged) EventType() Type { return TypeExperimentStateChanged }
+
+// BenchmarkCompleted is emitted when a benchmark run finishes
+type BenchmarkCompleted struct {
+	BenchmarkID  string             `json:"benchmark_id"`
+	ExperimentID string             `json:"experiment_id,omitempty"`
+	Passed       bool               `json:"passed"`
+	Duration     time.Duration      `json:"duration"`
+	Results      map[string]float64 `json:"results,omitempty"`
+}
+
+func (BenchmarkCompleted) EventType() Type { return TypeBenchmarkCompleted }
+
+// Bus publishes events and delivers them to subscribers
+type Bus interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe delivers events of the given types, or all events when no
+	// type is given, until ctx is cancelled
+	Subscribe(ctx context.Context, types ...Type) (<-chan Event, error)
+	Close() error
+}
+
+// NewEvent wraps a typed payload in an envelope
+func NewEvent(source string, payload Payload) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode %s event: %w", payload.EventType(), err)
+	}
+
+	return Event{
+		ID:     newID(),
+		Type:   payload.EventType(),
+		Source: source,
+		Time:   time.Now().UTC(),
+		Data:   data,
+	}, nil
+}
+
+// Publish sends a typed payload on the bus
+func Publish(ctx context.Context, bus Bus, source string, payload Payload) error {
+	event, err := NewEvent(source, payload)
+	if err != nil {
+		return err
+	}
+	return bus.Publish(ctx, event)
+}
+
+// Decode unmarshals the payload of an event
+func Decode[T Payload](event Event) (T, error) {
+	var payload T
+	if event.Type != payload.EventType() {
+		return payload, fmt.Errorf("event %s is %s, not %s", event.ID, event.Type, payload.EventType())
+	}
+	if err := json.Unmarshal(event.Data, &payload); err != nil {
+		return payload, fmt.Errorf("failed to decode %s event: %w", event.Type, err)
+	}
+	return payload, nil
+}
+
+// Handle subscribes to events of type T and calls handler for each until ctx
+// is cancelled. Handler errors are logged and do not stop the subscription.
+func Handle[T Payload](ctx context.Context, bus Bus, logger *zap.Logger, handler func(context.Context, Event, T) error) error {
+	var zero T
+	events, err := bus.Subscribe(ctx, zero.EventType())
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for event := range events {
+			payload, err := Decode[T](event)
+			if err == nil {
+				err = handler(ctx, event, payload)
+			}
+			if err != nil {
+				logger.Warn("failed to handle event",
+					zap.String("event_id", event.ID),
+					zap.String("type", string(event.Type)),
+					zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// Claimer records that a consumer handled an event, e.g. a
+// *store.PostgresDeliveryStore shared by every replica
+type Claimer interface {
+	// ClaimDelivery reports whether the caller is the first to claim the
+	// event for the consumer
+	ClaimDelivery(ctx context.Context, consumer, eventID string) (bool, error)
+}
+
+// Once wraps a handler so that every event is handled by one replica only.
+// The Postgres bus delivers each event to every subscribed process, so side
+// effects such as notifications would otherwise repeat per replica.
+func Once[T Payload](claimer Claimer, consumer string, handler func(context.Context, Event, T) error) func(context.Context, Event, T) error {
+	return func(ctx context.Context, event Event, payload T) error {
+		claimed, err := claimer.ClaimDelivery(ctx, consumer, event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to claim event: %w", err)
+		}
+		if !claimed {
+			return nil
+		}
+		return handler(ctx, event, payload)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "evt-" + hex.EncodeToString(b)
+}
+
+// matches reports whether an event type is in the subscription filter
+func matches(types []Type, t Type) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, want := range types {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestOnceAcrossReplicas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Every replica subscribes to the same bus, as with Postgres NOTIFY
+	bus := NewMemoryBus()
+	claims := &fakeClaimer{claimed: map[string]bool{}}
+	handled := make(chan string, 10)
+	for replica := 0; replica < 3; replica++ {
+		handler := Once(claims, "test", func(ctx context.Context, event Event, anomaly AnomalyDetected) error {
+			handled <- event.ID
+			return nil
+		})
+		if err := Handle(ctx, bus, zap.NewNop(), handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	event, err := NewEvent("detector", AnomalyDetected{Metric: "cardinality"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case id := <-handled:
+		if id != event.ID {
+			t.Fatalf("handled %s, want %s", id, event.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event was not handled")
+	}
+	claims.wait(t, 3)
+	select {
+	case id := <-handled:
+		t.Fatalf("event %s was handled twice", id)
+	default:
+	}
+}
+
+func TestOnceClaimError(t *testing.T) {
+	called := false
+	handler := Once(failingClaimer{}, "test", func(ctx context.Context, event Event, anomaly AnomalyDetected) error {
+		called = true
+		return nil
+	})
+	if err := handler(context.Background(), Event{ID: "evt-1"}, AnomalyDetected{}); err == nil {
+		t.Error("claim error was swallowed")
+	}
+	if called {
+		t.Error("event was handled without a claim")
+	}
+}
+
+type fakeClaimer struct {
+	mu      sync.Mutex
+	claimed map[string]bool
+	calls   int
+}
+
+func (f *fakeClaimer) ClaimDelivery(ctx context.Context, consumer, eventID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	key := consumer + "/" + eventID
+	if f.claimed[key] {
+		return false, nil
+	}
+	f.claimed[key] = true
+	return true, nil
+}
+
+// wait blocks until n claims were attempted
+func (f *fakeClaimer) wait(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		f.mu.Lock()
+		calls := f.calls
+		f.mu.Unlock()
+		if calls >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("fewer than %d replicas received the event", n)
+}
+
+type failingClaimer struct{}
+
+func (failingClaimer) ClaimDelivery(ctx context.Context, consumer, eventID string) (bool, error) {
+	return false, errors.New("database unavailable")
+}
@@ -0,0 +1,94 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const subscriberBuffer = 64
+
+// ErrClosed is returned when publishing to or subscribing on a closed bus
+var ErrClosed = errors.New("event bus closed")
+
+type subscriber struct {
+	types  []Type
+	events chan Event
+}
+
+// MemoryBus delivers events within a single process. It is used when no
+// shared backend is configured and by the Postgres bus for local fan-out.
+type MemoryBus struct {
+	mu     sync.RWMutex
+	subs   map[*subscriber]struct{}
+	closed bool
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subs: make(map[*subscriber]struct{})}
+}
+
+// Publish delivers the event to every matching subscriber. Subscribers that
+// fall behind by more than their buffer miss events rather than blocking the
+// publisher.
+func (b *MemoryBus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	for sub := range b.subs {
+		if !matches(sub.types, event.Type) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(ctx context.Context, types ...Type) (<-chan Event, error) {
+	sub := &subscriber{
+		types:  types,
+		events: make(chan Event, subscriberBuffer),
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub.events)
+		}
+	}()
+
+	return sub.events, nil
+}
+
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package eventbus
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const (
+	// notifyChannel is the Postgres channel all Phoenix events are sent on
+	notifyChannel = "phoenix_events"
+
+	// maxNotifyPayload is the Postgres limit on NOTIFY payload size
+	maxNotifyPayload = 8000
+)
+
+// PostgresBus shares events between services through Postgres
+// LISTEN/NOTIFY, using the database every service already depends on
+type PostgresBus struct {
+	db       *sql.DB
+	listener *pq.Listener
+	local    *MemoryBus
+	logger   *zap.Logger
+	done     chan struct{}
+}
+
+// NewPostgresBus publishes through db and listens on a dedicated connection
+// opened from dsn
+func NewPostgresBus(db *sql.DB, dsn string, logger *zap.Logger) (*PostgresBus, error) {
+	listener := pq.NewListener(dsn, 1*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("event bus listener connection problem", zap.Error(err))
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", notifyChannel, err)
+	}
+
+	b := &PostgresBus{
+		db:       db,
+		listener: listener,
+		local:    NewMemoryBus(),
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+	go b.dispatch()
+	return b, nil
+}
+
+func (b *PostgresBus) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("event %s is %d bytes, exceeding the %d byte NOTIFY limit", event.Type, len(payload), maxNotifyPayload)
+	}
+
+	if _, err := b.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", notifyChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+func (b *PostgresBus) Subscribe(ctx context.Context, types ...Type) (<-chan Event, error) {
+	return b.local.Subscribe(ctx, types...)
+}
+
+func (b *PostgresBus) Close() error {
+	close(b.done)
+	b.local.Close()
+	return b.listener.Close()
+}
+
+// dispatch fans notifications out to local subscribers
+func (b *PostgresBus) dispatch() {
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case n := <-b.listener.Notify:
+			// A nil notification means the connection was re-established;
+			// anything sent while disconnected is lost
+			if n == nil {
+				b.logger.Info("event bus listener reconnected")
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				b.logger.Warn("dropping malformed event", zap.Error(err))
+				continue
+			}
+			b.local.Publish(context.Background(), event)
+
+		case <-ping.C:
+			go b.listener.Ping()
+
+		case <-b.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultDatadogSite = "datadoghq.com"
+
+// DatadogExporter sends experiment summaries through the Datadog Events API
+type DatadogExporter struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewDatadogExporter(apiKey, site string) (*DatadogExporter, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("datadog: API key is required")
+	}
+	if site == "" {
+		site = defaultDatadogSite
+	}
+
+	return &DatadogExporter{
+		endpoint: fmt.Sprintf("https://api.%s/api/v1/events", site),
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (e *DatadogExporter) Name() string {
+	return "datadog"
+}
+
+func (e *DatadogExporter) ExportExperimentResult(ctx context.Context, summary *ExperimentSummary) error {
+	alertType := "success"
+	if summary.Verdict == VerdictFailed || summary.Verdict == VerdictAborted {
+		alertType = "error"
+	}
+
+	event := map[string]interface{}{
+		"title": fmt.Sprintf("Phoenix experiment %s %s", summary.Name, summary.Verdict),
+		"text": fmt.Sprintf("%s\nCardinality reduction: %.1f%%\nCost reduction: %.1f%%",
+			summary.Message, summary.CardinalityReductionPercent, summary.CostReductionPercent),
+		"date_happened":    summary.Timestamp.Unix(),
+		"alert_type":       alertType,
+		"source_type_name": "phoenix",
+		"aggregation_key":  summary.ExperimentID,
+		"tags": []string{
+			"experiment_id:" + summary.ExperimentID,
+			"verdict:" + summary.Verdict,
+			"variant:" + summary.Variant,
+			"owner:" + summary.Owner,
+		},
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", e.apiKey)
+
+	return doRequest(e.client, req)
+}
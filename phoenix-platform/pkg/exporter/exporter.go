@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExperimentSummary is the payload pushed to external observability backends
+// when an experiment reaches a verdict.
+type ExperimentSummary struct {
+	ExperimentID                string
+	Name                        string
+	Owner                       string
+	Phase                       string
+	Verdict                     string
+	Variant                     string
+	Message                     string
+	BaselineCardinality         int64
+	VariantCardinality          int64
+	CardinalityReductionPercent float64
+	CostReductionPercent        float64
+	Timestamp                   time.Time
+}
+
+// Verdicts reported in ExperimentSummary.Verdict
+const (
+	VerdictPromoted = "promoted"
+	VerdictFailed   = "failed"
+	VerdictAborted  = "aborted"
+)
+
+// Exporter pushes experiment summaries to an external system
+type Exporter interface {
+	Name() string
+	ExportExperimentResult(ctx context.Context, summary *ExperimentSummary) error
+}
+
+// Multi fans a summary out to every configured exporter. A failing exporter
+// does not prevent the others from receiving the summary.
+type Multi []Exporter
+
+func (m Multi) Name() string {
+	names := make([]string, len(m))
+	for i, e := range m {
+		names[i] = e.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (m Multi) ExportExperimentResult(ctx context.Context, summary *ExperimentSummary) error {
+	var errs []error
+	for _, e := range m {
+		if err := e.ExportExperimentResult(ctx, summary); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Config selects and configures the exporters enabled for a deployment
+type Config struct {
+	// Enabled is a list of exporter names: "newrelic", "datadog"
+	Enabled []string
+
+	NewRelicAccountID string
+	NewRelicInsertKey string
+	NewRelicRegion    string
+
+	DatadogAPIKey string
+	DatadogSite   string
+}
+
+// New builds the exporters listed in cfg.Enabled. It returns nil when no
+// exporter is enabled.
+func New(cfg Config) (Exporter, error) {
+	var exporters Multi
+	for _, name := range cfg.Enabled {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case "":
+			continue
+		case "newrelic":
+			e, err := NewNewRelicExporter(cfg.NewRelicAccountID, cfg.NewRelicInsertKey, cfg.NewRelicRegion)
+			if err != nil {
+				return nil, err
+			}
+			exporters = append(exporters, e)
+		case "datadog":
+			e, err := NewDatadogExporter(cfg.DatadogAPIKey, cfg.DatadogSite)
+			if err != nil {
+				return nil, err
+			}
+			exporters = append(exporters, e)
+		default:
+			return nil, fmt.Errorf("unknown result exporter: %s", name)
+		}
+	}
+
+	if len(exporters) == 0 {
+		return nil, nil
+	}
+	return exporters, nil
+}
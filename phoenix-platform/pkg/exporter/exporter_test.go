@@ -0,0 +1,259 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capture records the last request a backend received
+type capture struct {
+	header http.Header
+	body   []byte
+}
+
+func newBackend(t *testing.T, status int) (*httptest.Server, *capture) {
+	t.Helper()
+	c := &capture{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.header = r.Header.Clone()
+		c.body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, c
+}
+
+func testSummary(verdict string) *ExperimentSummary {
+	return &ExperimentSummary{
+		ExperimentID:                "exp-1",
+		Name:                        "topk",
+		Owner:                       "alice",
+		Phase:                       "PHASE_COMPLETED",
+		Verdict:                     verdict,
+		Variant:                     "candidate",
+		Message:                     "Candidate met the success criteria",
+		BaselineCardinality:         1000,
+		VariantCardinality:          250,
+		CardinalityReductionPercent: 75,
+		CostReductionPercent:        60.4,
+		Timestamp:                   time.Unix(1700000000, 0),
+	}
+}
+
+func TestNewRelicEndpoint(t *testing.T) {
+	tests := []struct {
+		region string
+		want   string
+	}{
+		{"", "https://insights-collector.newrelic.com/v1/accounts/42/events"},
+		{"us", "https://insights-collector.newrelic.com/v1/accounts/42/events"},
+		{"EU", "https://insights-collector.eu01.nr-data.net/v1/accounts/42/events"},
+	}
+	for _, tt := range tests {
+		e, err := NewNewRelicExporter("42", "key", tt.region)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.endpoint != tt.want {
+			t.Errorf("region %q: endpoint = %s, want %s", tt.region, e.endpoint, tt.want)
+		}
+	}
+
+	if _, err := NewNewRelicExporter("", "key", ""); err == nil {
+		t.Error("missing account ID was accepted")
+	}
+	if _, err := NewNewRelicExporter("42", "", ""); err == nil {
+		t.Error("missing insert key was accepted")
+	}
+}
+
+func TestNewRelicPayload(t *testing.T) {
+	server, got := newBackend(t, http.StatusOK)
+	e, err := NewNewRelicExporter("42", "insert-key", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.endpoint = server.URL
+
+	if err := e.ExportExperimentResult(context.Background(), testSummary(VerdictPromoted)); err != nil {
+		t.Fatal(err)
+	}
+
+	if key := got.header.Get("X-Insert-Key"); key != "insert-key" {
+		t.Errorf("X-Insert-Key = %q", key)
+	}
+	var events []map[string]interface{}
+	if err := json.Unmarshal(got.body, &events); err != nil {
+		t.Fatalf("payload is not a JSON array of events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	want := map[string]interface{}{
+		"eventType":                   "PhoenixExperimentResult",
+		"timestamp":                   float64(1700000000),
+		"experimentId":                "exp-1",
+		"experimentName":              "topk",
+		"owner":                       "alice",
+		"phase":                       "PHASE_COMPLETED",
+		"verdict":                     "promoted",
+		"variant":                     "candidate",
+		"message":                     "Candidate met the success criteria",
+		"baselineCardinality":         float64(1000),
+		"variantCardinality":          float64(250),
+		"cardinalityReductionPercent": float64(75),
+		"costReductionPercent":        60.4,
+	}
+	for key, value := range want {
+		if events[0][key] != value {
+			t.Errorf("%s = %v, want %v", key, events[0][key], value)
+		}
+	}
+}
+
+func TestDatadogEndpoint(t *testing.T) {
+	e, err := NewDatadogExporter("key", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://api.datadoghq.com/api/v1/events"; e.endpoint != want {
+		t.Errorf("endpoint = %s, want %s", e.endpoint, want)
+	}
+	e, err = NewDatadogExporter("key", "datadoghq.eu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://api.datadoghq.eu/api/v1/events"; e.endpoint != want {
+		t.Errorf("endpoint = %s, want %s", e.endpoint, want)
+	}
+	if _, err := NewDatadogExporter("", ""); err == nil {
+		t.Error("missing API key was accepted")
+	}
+}
+
+func TestDatadogPayload(t *testing.T) {
+	tests := []struct {
+		verdict   string
+		alertType string
+	}{
+		{VerdictPromoted, "success"},
+		{VerdictFailed, "error"},
+		{VerdictAborted, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.verdict, func(t *testing.T) {
+			server, got := newBackend(t, http.StatusAccepted)
+			e, err := NewDatadogExporter("api-key", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.endpoint = server.URL
+
+			if err := e.ExportExperimentResult(context.Background(), testSummary(tt.verdict)); err != nil {
+				t.Fatal(err)
+			}
+
+			if key := got.header.Get("DD-API-KEY"); key != "api-key" {
+				t.Errorf("DD-API-KEY = %q", key)
+			}
+			var event struct {
+				Title          string   `json:"title"`
+				Text           string   `json:"text"`
+				DateHappened   int64    `json:"date_happened"`
+				AlertType      string   `json:"alert_type"`
+				SourceTypeName string   `json:"source_type_name"`
+				AggregationKey string   `json:"aggregation_key"`
+				Tags           []string `json:"tags"`
+			}
+			if err := json.Unmarshal(got.body, &event); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := "Phoenix experiment topk " + tt.verdict; event.Title != want {
+				t.Errorf("title = %q, want %q", event.Title, want)
+			}
+			if want := "Candidate met the success criteria\nCardinality reduction: 75.0%\nCost reduction: 60.4%"; event.Text != want {
+				t.Errorf("text = %q, want %q", event.Text, want)
+			}
+			if event.DateHappened != 1700000000 || event.AlertType != tt.alertType || event.SourceTypeName != "phoenix" || event.AggregationKey != "exp-1" {
+				t.Errorf("event = %+v", event)
+			}
+			wantTags := []string{"experiment_id:exp-1", "verdict:" + tt.verdict, "variant:candidate", "owner:alice"}
+			if strings.Join(event.Tags, ",") != strings.Join(wantTags, ",") {
+				t.Errorf("tags = %v, want %v", event.Tags, wantTags)
+			}
+		})
+	}
+}
+
+func TestExportRejectsErrorStatus(t *testing.T) {
+	server, _ := newBackend(t, http.StatusForbidden)
+	nr, _ := NewNewRelicExporter("42", "key", "")
+	nr.endpoint = server.URL
+	dd, _ := NewDatadogExporter("key", "")
+	dd.endpoint = server.URL
+
+	for _, e := range []Exporter{nr, dd} {
+		if err := e.ExportExperimentResult(context.Background(), testSummary(VerdictPromoted)); err == nil || !strings.Contains(err.Error(), "403") {
+			t.Errorf("%s: err = %v, want the status code", e.Name(), err)
+		}
+	}
+}
+
+type fakeExporter struct {
+	name     string
+	err      error
+	received int
+}
+
+func (f *fakeExporter) Name() string { return f.name }
+
+func (f *fakeExporter) ExportExperimentResult(ctx context.Context, summary *ExperimentSummary) error {
+	f.received++
+	return f.err
+}
+
+func TestMultiExportsToAll(t *testing.T) {
+	failing := &fakeExporter{name: "failing", err: errors.New("boom")}
+	ok := &fakeExporter{name: "ok"}
+	m := Multi{failing, ok}
+
+	err := m.ExportExperimentResult(context.Background(), testSummary(VerdictPromoted))
+	if err == nil || !strings.Contains(err.Error(), "failing: boom") {
+		t.Errorf("err = %v, want the failing exporter named", err)
+	}
+	if failing.received != 1 || ok.received != 1 {
+		t.Error("a failing exporter kept the others from receiving the summary")
+	}
+	if m.Name() != "failing,ok" {
+		t.Errorf("name = %s", m.Name())
+	}
+}
+
+func TestNew(t *testing.T) {
+	e, err := New(Config{Enabled: []string{" NewRelic ", "", "datadog"}, NewRelicAccountID: "42", NewRelicInsertKey: "key", DatadogAPIKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Name() != "newrelic,datadog" {
+		t.Errorf("name = %s", e.Name())
+	}
+
+	if e, err := New(Config{}); e != nil || err != nil {
+		t.Errorf("no exporters enabled: got %v, %v", e, err)
+	}
+	if _, err := New(Config{Enabled: []string{"splunk"}}); err == nil {
+		t.Error("unknown exporter was accepted")
+	}
+	if _, err := New(Config{Enabled: []string{"datadog"}}); err == nil {
+		t.Error("datadog without an API key was accepted")
+	}
+}
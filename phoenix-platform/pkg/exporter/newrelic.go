@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	newRelicEventType = "PhoenixExperimentResult"
+	newRelicUSHost    = "insights-collector.newrelic.com"
+	newRelicEUHost    = "insights-collector.eu01.nr-data.net"
+)
+
+// NewRelicExporter sends experiment summaries as custom events through the
+// New Relic Event API
+type NewRelicExporter struct {
+	endpoint  string
+	insertKey string
+	client    *http.Client
+}
+
+func NewNewRelicExporter(accountID, insertKey, region string) (*NewRelicExporter, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("newrelic: account ID is required")
+	}
+	if insertKey == "" {
+		return nil, fmt.Errorf("newrelic: insert key is required")
+	}
+
+	host := newRelicUSHost
+	if strings.EqualFold(region, "eu") {
+		host = newRelicEUHost
+	}
+
+	return &NewRelicExporter{
+		endpoint:  fmt.Sprintf("https://%s/v1/accounts/%s/events", host, accountID),
+		insertKey: insertKey,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (e *NewRelicExporter) Name() string {
+	return "newrelic"
+}
+
+func (e *NewRelicExporter) ExportExperimentResult(ctx context.Context, summary *ExperimentSummary) error {
+	event := map[string]interface{}{
+		"eventType":                   newRelicEventType,
+		"timestamp":                   summary.Timestamp.Unix(),
+		"experimentId":                summary.ExperimentID,
+		"experimentName":              summary.Name,
+		"owner":                       summary.Owner,
+		"phase":                       summary.Phase,
+		"verdict":                     summary.Verdict,
+		"variant":                     summary.Variant,
+		"message":                     summary.Message,
+		"baselineCardinality":         summary.BaselineCardinality,
+		"variantCardinality":          summary.VariantCardinality,
+		"cardinalityReductionPercent": summary.CardinalityReductionPercent,
+		"costReductionPercent":        summary.CostReductionPercent,
+	}
+
+	body, err := json.Marshal([]interface{}{event})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Insert-Key", e.insertKey)
+
+	return doRequest(e.client, req)
+}
+
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return nil
+}
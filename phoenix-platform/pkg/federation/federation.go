@@ -0,0 +1,328 @@
+// Package federation aggregates agent and experiment state from the
+// cluster-local platform APIs of several clusters and rolls experiments out
+// across them. Each cluster is polled independently, so an unreachable
+// cluster only marks its own view as stale.
+package federation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/client"
+)
+
+// pageSize is used when paging through cluster-local lists
+const pageSize = 100
+
+// ClusterConfig describes one cluster-local platform API
+type ClusterConfig struct {
+	Name     string `yaml:"name"`
+	Endpoint string `yaml:"endpoint"`
+	// TokenFile holds the bearer token used for this cluster only
+	TokenFile string `yaml:"token_file"`
+	Insecure  bool   `yaml:"insecure"`
+}
+
+// LoadClusters reads the cluster list from a YAML file of the form
+// "clusters: [{name, endpoint, token_file, insecure}]"
+func LoadClusters(path string) ([]ClusterConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file struct {
+		Clusters []ClusterConfig `yaml:"clusters"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return file.Clusters, nil
+}
+
+// ClusterState is the last known view of one cluster
+type ClusterState struct {
+	Name        string
+	Endpoint    string
+	Healthy     bool
+	Error       string
+	LastAttempt time.Time
+	LastSync    time.Time
+	Agents      []*pb.Agent
+	Experiments []*pb.Experiment
+}
+
+// CardinalityEstimate sums the estimates reported by the cluster's agents
+func (s ClusterState) CardinalityEstimate() int64 {
+	var total int64
+	for _, a := range s.Agents {
+		total += a.CardinalityEstimate
+	}
+	return total
+}
+
+// clusterClient is the part of the platform API client the federator uses
+type clusterClient interface {
+	ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error)
+	ListExperiments(ctx context.Context, opts client.ListOptions) ([]*pb.Experiment, int32, error)
+	CreateExperiment(ctx context.Context, spec *pb.ExperimentSpec) (string, error)
+	Close() error
+}
+
+type cluster struct {
+	config ClusterConfig
+	client clusterClient
+	state  ClusterState
+}
+
+// Federator polls every configured cluster
+type Federator struct {
+	order    []string
+	clusters map[string]*cluster
+	interval time.Duration
+	timeout  time.Duration
+	logger   *zap.Logger
+
+	mu sync.RWMutex
+}
+
+// New connects to every cluster. Clusters are polled every interval and each
+// poll of a cluster must finish within timeout.
+func New(configs []ClusterConfig, interval, timeout time.Duration, logger *zap.Logger) (*Federator, error) {
+	f := &Federator{
+		clusters: make(map[string]*cluster, len(configs)),
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+	}
+
+	for _, cfg := range configs {
+		if cfg.Name == "" || cfg.Endpoint == "" {
+			f.Close()
+			return nil, fmt.Errorf("cluster %q: name and endpoint are required", cfg.Name)
+		}
+		if _, ok := f.clusters[cfg.Name]; ok {
+			f.Close()
+			return nil, fmt.Errorf("cluster %q is configured twice", cfg.Name)
+		}
+
+		var opts []client.Option
+		if cfg.Insecure {
+			opts = append(opts, client.WithInsecure())
+		}
+		if cfg.TokenFile != "" {
+			token, err := os.ReadFile(cfg.TokenFile)
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("cluster %q: failed to read token: %w", cfg.Name, err)
+			}
+			opts = append(opts, client.WithToken(strings.TrimSpace(string(token))))
+		}
+
+		c, err := client.New(cfg.Endpoint, opts...)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("cluster %q: %w", cfg.Name, err)
+		}
+
+		f.order = append(f.order, cfg.Name)
+		f.clusters[cfg.Name] = &cluster{
+			config: cfg,
+			client: c,
+			state:  ClusterState{Name: cfg.Name, Endpoint: cfg.Endpoint},
+		}
+	}
+	return f, nil
+}
+
+// Run polls every cluster immediately and then every interval until ctx is
+// cancelled
+func (f *Federator) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		f.Sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync polls every cluster concurrently
+func (f *Federator) Sync(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, name := range f.order {
+		wg.Add(1)
+		go func(c *cluster) {
+			defer wg.Done()
+			f.syncCluster(ctx, c)
+		}(f.clusters[name])
+	}
+	wg.Wait()
+}
+
+func (f *Federator) syncCluster(ctx context.Context, c *cluster) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	agents, agentsErr := listAgents(ctx, c.client)
+	experiments, experimentsErr := listExperiments(ctx, c.client)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c.state.LastAttempt = time.Now()
+	if err := firstError(agentsErr, experimentsErr); err != nil {
+		// Keep the last good snapshot so global views degrade instead of
+		// losing the cluster entirely
+		c.state.Healthy = false
+		c.state.Error = err.Error()
+		f.logger.Warn("failed to sync cluster",
+			zap.String("cluster", c.config.Name),
+			zap.Error(err))
+		return
+	}
+
+	c.state.Healthy = true
+	c.state.Error = ""
+	c.state.LastSync = c.state.LastAttempt
+	c.state.Agents = agents
+	c.state.Experiments = experiments
+}
+
+// Clusters returns the state of every cluster in configuration order
+func (f *Federator) Clusters() []ClusterState {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	states := make([]ClusterState, len(f.order))
+	for i, name := range f.order {
+		states[i] = f.clusters[name].state
+	}
+	return states
+}
+
+// Cluster returns the state of one cluster
+func (f *Federator) Cluster(name string) (ClusterState, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	c, ok := f.clusters[name]
+	if !ok {
+		return ClusterState{}, false
+	}
+	return c.state, true
+}
+
+// Rollout result states
+const (
+	RolloutCreated = "created"
+	RolloutFailed  = "failed"
+	RolloutSkipped = "skipped"
+)
+
+// RolloutResult is the outcome of creating an experiment in one cluster
+type RolloutResult struct {
+	Cluster      string
+	Status       string
+	ExperimentID string
+	Error        string
+}
+
+// Rollout creates the experiment in each named cluster in order, or in every
+// cluster when names is empty. With stopOnFailure the clusters after the
+// first failure are skipped.
+func (f *Federator) Rollout(ctx context.Context, spec *pb.ExperimentSpec, names []string, stopOnFailure bool) ([]RolloutResult, error) {
+	if len(names) == 0 {
+		names = f.order
+	}
+	for _, name := range names {
+		if _, ok := f.clusters[name]; !ok {
+			return nil, fmt.Errorf("unknown cluster: %s", name)
+		}
+	}
+
+	results := make([]RolloutResult, 0, len(names))
+	failed := false
+	for _, name := range names {
+		result := RolloutResult{Cluster: name}
+		if failed && stopOnFailure {
+			result.Status = RolloutSkipped
+			results = append(results, result)
+			continue
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, f.timeout)
+		id, err := f.clusters[name].client.CreateExperiment(callCtx, spec)
+		cancel()
+		if err != nil {
+			failed = true
+			result.Status = RolloutFailed
+			result.Error = err.Error()
+			f.logger.Warn("cluster rollout failed",
+				zap.String("cluster", name),
+				zap.Error(err))
+		} else {
+			result.Status = RolloutCreated
+			result.ExperimentID = id
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Close releases every cluster connection
+func (f *Federator) Close() error {
+	for _, c := range f.clusters {
+		c.client.Close()
+	}
+	return nil
+}
+
+func listAgents(ctx context.Context, c clusterClient) ([]*pb.Agent, error) {
+	var agents []*pb.Agent
+	for {
+		resp, err := c.ListAgents(ctx, &pb.ListAgentsRequest{Limit: pageSize, Offset: int32(len(agents))})
+		if err != nil {
+			return nil, fmt.Errorf("list agents: %w", err)
+		}
+		agents = append(agents, resp.Agents...)
+		if len(resp.Agents) == 0 || len(agents) >= int(resp.Total) {
+			return agents, nil
+		}
+	}
+}
+
+func listExperiments(ctx context.Context, c clusterClient) ([]*pb.Experiment, error) {
+	var experiments []*pb.Experiment
+	for {
+		page, total, err := c.ListExperiments(ctx, client.ListOptions{Limit: pageSize, Offset: int32(len(experiments))})
+		if err != nil {
+			return nil, fmt.Errorf("list experiments: %w", err)
+		}
+		experiments = append(experiments, page...)
+		if len(page) == 0 || len(experiments) >= int(total) {
+			return experiments, nil
+		}
+	}
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	pb "github.com/phoenix/platform/pkg/api/v1"
+	"github.com/phoenix/platform/pkg/client"
+)
+
+// fakeClient serves one cluster's agents and experiments from memory
+type fakeClient struct {
+	agents      []*pb.Agent
+	experiments []*pb.Experiment
+	err         error
+	created     []*pb.ExperimentSpec
+}
+
+func (c *fakeClient) ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &pb.ListAgentsResponse{Agents: page(c.agents, req.Offset, req.Limit), Total: int32(len(c.agents))}, nil
+}
+
+func (c *fakeClient) ListExperiments(ctx context.Context, opts client.ListOptions) ([]*pb.Experiment, int32, error) {
+	if c.err != nil {
+		return nil, 0, c.err
+	}
+	return page(c.experiments, opts.Offset, opts.Limit), int32(len(c.experiments)), nil
+}
+
+func (c *fakeClient) CreateExperiment(ctx context.Context, spec *pb.ExperimentSpec) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	c.created = append(c.created, spec)
+	return "exp-1", nil
+}
+
+func (c *fakeClient) Close() error { return nil }
+
+func page[T any](items []T, offset, limit int32) []T {
+	if int(offset) >= len(items) {
+		return nil
+	}
+	end := int(offset + limit)
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
+
+func newFederator(clients map[string]*fakeClient, order ...string) *Federator {
+	f := &Federator{
+		order:    order,
+		clusters: make(map[string]*cluster, len(order)),
+		interval: time.Minute,
+		timeout:  time.Second,
+		logger:   zap.NewNop(),
+	}
+	for _, name := range order {
+		f.clusters[name] = &cluster{
+			config: ClusterConfig{Name: name},
+			client: clients[name],
+			state:  ClusterState{Name: name},
+		}
+	}
+	return f
+}
+
+func TestSyncKeepsLastSnapshotOfUnhealthyCluster(t *testing.T) {
+	agents := make([]*pb.Agent, pageSize+5)
+	for i := range agents {
+		agents[i] = &pb.Agent{Id: "agent", CardinalityEstimate: 10}
+	}
+	east := &fakeClient{agents: agents, experiments: []*pb.Experiment{{Id: "exp-1"}}}
+	west := &fakeClient{agents: agents[:1]}
+	f := newFederator(map[string]*fakeClient{"east": east, "west": west}, "east", "west")
+
+	f.Sync(context.Background())
+	before, _ := f.Cluster("east")
+	if !before.Healthy || len(before.Agents) != len(agents) || len(before.Experiments) != 1 {
+		t.Fatalf("first sync: healthy=%v agents=%d experiments=%d", before.Healthy, len(before.Agents), len(before.Experiments))
+	}
+
+	east.err = errors.New("connection refused")
+	f.Sync(context.Background())
+
+	after, _ := f.Cluster("east")
+	if after.Healthy || !strings.Contains(after.Error, "connection refused") {
+		t.Errorf("healthy=%v error=%q, want the cluster marked unhealthy", after.Healthy, after.Error)
+	}
+	if len(after.Agents) != len(agents) || len(after.Experiments) != 1 || after.CardinalityEstimate() != before.CardinalityEstimate() {
+		t.Error("unhealthy cluster lost its last snapshot")
+	}
+	if !after.LastSync.Equal(before.LastSync) || !after.LastAttempt.After(before.LastAttempt) {
+		t.Errorf("last sync %v -> %v, last attempt %v -> %v", before.LastSync, after.LastSync, before.LastAttempt, after.LastAttempt)
+	}
+	if other, _ := f.Cluster("west"); !other.Healthy {
+		t.Error("a failing cluster marked another one unhealthy")
+	}
+}
+
+func TestRolloutStopOnFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		stopOnFailure bool
+		want          []string
+	}{
+		{"continue", false, []string{RolloutCreated, RolloutFailed, RolloutCreated}},
+		{"stop", true, []string{RolloutCreated, RolloutFailed, RolloutSkipped}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := map[string]*fakeClient{
+				"a": {},
+				"b": {err: errors.New("permission denied")},
+				"c": {},
+			}
+			f := newFederator(clients, "a", "b", "c")
+
+			results, err := f.Rollout(context.Background(), &pb.ExperimentSpec{}, nil, tt.stopOnFailure)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.want))
+			}
+			for i, r := range results {
+				if r.Cluster != f.order[i] || r.Status != tt.want[i] {
+					t.Errorf("result %d = %s %s, want %s %s", i, r.Cluster, r.Status, f.order[i], tt.want[i])
+				}
+			}
+			if results[1].Error == "" {
+				t.Error("failed rollout has no error")
+			}
+			if created := len(clients["c"].created); (created == 0) != tt.stopOnFailure {
+				t.Errorf("cluster c got %d experiments", created)
+			}
+		})
+	}
+}
+
+func TestRolloutRejectsUnknownClusters(t *testing.T) {
+	clients := map[string]*fakeClient{"a": {}}
+	f := newFederator(clients, "a")
+
+	if _, err := f.Rollout(context.Background(), &pb.ExperimentSpec{}, []string{"a", "missing"}, false); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("err = %v, want the unknown cluster named", err)
+	}
+	if len(clients["a"].created) != 0 {
+		t.Error("experiment was created before the cluster list was checked")
+	}
+}
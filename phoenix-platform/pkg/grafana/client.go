@@ -0,0 +1,116 @@
+// Package grafana provisions per-experiment comparison dashboards through the
+// Grafana HTTP API.
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client is a minimal Grafana HTTP API client
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// SaveResult is returned by SaveDashboard
+type SaveResult struct {
+	ID      int64  `json:"id"`
+	UID     string `json:"uid"`
+	URL     string `json:"url"`
+	Status  string `json:"status"`
+	Version int    `json:"version"`
+}
+
+// SaveDashboard creates or overwrites a dashboard in the given folder
+func (c *Client) SaveDashboard(ctx context.Context, dashboard map[string]interface{}, folderUID string) (*SaveResult, error) {
+	body := map[string]interface{}{
+		"dashboard": dashboard,
+		"overwrite": true,
+		"message":   "provisioned by phoenix-api",
+	}
+	if folderUID != "" {
+		body["folderUid"] = folderUID
+	}
+
+	result := &SaveResult{}
+	if err := c.do(ctx, http.MethodPost, "/api/dashboards/db", body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDashboard returns the dashboard model stored under uid
+func (c *Client) GetDashboard(ctx context.Context, uid string) (map[string]interface{}, error) {
+	var resp struct {
+		Dashboard map[string]interface{} `json:"dashboard"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/dashboards/uid/"+uid, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Dashboard, nil
+}
+
+// DeleteDashboard removes the dashboard stored under uid
+func (c *Client) DeleteDashboard(ctx context.Context, uid string) error {
+	return c.do(ctx, http.MethodDelete, "/api/dashboards/uid/"+uid, nil, nil)
+}
+
+// DashboardURL returns the absolute URL for a path returned by the API
+func (c *Client) DashboardURL(path string) string {
+	return c.baseURL + path
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("grafana %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
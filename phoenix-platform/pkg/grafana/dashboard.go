@@ -0,0 +1,89 @@
+package grafana
+
+import (
+	"fmt"
+
+	sc "github.com/phoenix/platform/pkg/semconv"
+)
+
+// dashboardUID is deterministic so re-provisioning replaces the dashboard
+func dashboardUID(experimentID string) string {
+	return "phoenix-" + experimentID
+}
+
+// experimentDashboard builds a baseline vs candidate comparison dashboard
+// scoped to one experiment
+func experimentDashboard(experimentID, experimentName, datasourceUID string) map[string]interface{} {
+	experiment := sc.Eq(sc.LabelExperimentID, experimentID)
+	byVariant := func(metric string) string {
+		return sc.SumBy(sc.Selector(metric, experiment), sc.LabelVariant)
+	}
+
+	panels := []map[string]interface{}{
+		timeseriesPanel(1, "Process cardinality", 0, 0,
+			byVariant(sc.ProcessCardinality), "short", datasourceUID),
+		statPanel(2, "Cardinality reduction", 12, 0,
+			sc.Selector(sc.RuleCardinalityReduction, experiment), "percent", datasourceUID),
+		timeseriesPanel(3, "Collector CPU (cores)", 0, 8,
+			byVariant(sc.RuleCollectorCPU), "short", datasourceUID),
+		timeseriesPanel(4, "Collector memory", 12, 8,
+			byVariant(sc.RuleCollectorMemory), "bytes", datasourceUID),
+		statPanel(5, "Critical process coverage", 0, 16,
+			sc.Selector(sc.RuleCriticalProcessCoverage, experiment), "percent", datasourceUID),
+		timeseriesPanel(6, "Estimated hourly cost", 12, 16,
+			byVariant(sc.RuleEstimatedCostHourly), "currencyUSD", datasourceUID),
+		// Recorded once per variant already; a ratio must not be summed
+		timeseriesPanel(7, "Signal preservation", 0, 24,
+			sc.Selector(sc.RuleSignalPreservation, experiment), "percentunit", datasourceUID),
+	}
+
+	return map[string]interface{}{
+		"uid":           dashboardUID(experimentID),
+		"title":         fmt.Sprintf("Phoenix experiment: %s (%s)", experimentName, experimentID),
+		"tags":          []interface{}{"phoenix", "experiment", experimentID},
+		"timezone":      "browser",
+		"schemaVersion": 38,
+		"refresh":       "30s",
+		"time": map[string]interface{}{
+			"from": "now-6h",
+			"to":   "now",
+		},
+		"panels": panels,
+	}
+}
+
+func timeseriesPanel(id int, title string, x, y int, expr, unit, datasourceUID string) map[string]interface{} {
+	panel := basePanel(id, "timeseries", title, x, y, expr, unit, datasourceUID)
+	panel["targets"] = []map[string]interface{}{
+		{"refId": "A", "expr": expr, "legendFormat": "{{variant}}", "datasource": datasource(datasourceUID)},
+	}
+	return panel
+}
+
+func statPanel(id int, title string, x, y int, expr, unit, datasourceUID string) map[string]interface{} {
+	return basePanel(id, "stat", title, x, y, expr, unit, datasourceUID)
+}
+
+func basePanel(id int, panelType, title string, x, y int, expr, unit, datasourceUID string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         id,
+		"type":       panelType,
+		"title":      title,
+		"datasource": datasource(datasourceUID),
+		"gridPos":    map[string]int{"x": x, "y": y, "w": 12, "h": 8},
+		"fieldConfig": map[string]interface{}{
+			"defaults": map[string]interface{}{"unit": unit},
+		},
+		"targets": []map[string]interface{}{
+			{"refId": "A", "expr": expr, "datasource": datasource(datasourceUID)},
+		},
+	}
+}
+
+func datasource(uid string) map[string]string {
+	ds := map[string]string{"type": "prometheus"}
+	if uid != "" {
+		ds["uid"] = uid
+	}
+	return ds
+}
@@ -0,0 +1,87 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+)
+
+// Actions applied to an experiment dashboard when the experiment ends
+const (
+	OnCompleteKeep    = "keep"
+	OnCompleteArchive = "archive"
+	OnCompleteDelete  = "delete"
+)
+
+// Config controls where dashboards are created and what happens to them
+// when the experiment ends
+type Config struct {
+	URL              string
+	Token            string
+	FolderUID        string
+	ArchiveFolderUID string
+	DatasourceUID    string
+	OnComplete       string
+}
+
+// Provisioner creates and retires per-experiment dashboards
+type Provisioner struct {
+	client *Client
+	config Config
+}
+
+func NewProvisioner(config Config) (*Provisioner, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("grafana URL is required")
+	}
+
+	switch config.OnComplete {
+	case "":
+		config.OnComplete = OnCompleteKeep
+	case OnCompleteKeep, OnCompleteDelete:
+	case OnCompleteArchive:
+		if config.ArchiveFolderUID == "" {
+			return nil, fmt.Errorf("archive folder UID is required when dashboards are archived")
+		}
+	default:
+		return nil, fmt.Errorf("unknown dashboard completion action: %s", config.OnComplete)
+	}
+
+	return &Provisioner{
+		client: NewClient(config.URL, config.Token),
+		config: config,
+	}, nil
+}
+
+// Provision creates (or replaces) the comparison dashboard for an experiment
+// and returns its URL
+func (p *Provisioner) Provision(ctx context.Context, experimentID, experimentName string) (string, error) {
+	dashboard := experimentDashboard(experimentID, experimentName, p.config.DatasourceUID)
+	result, err := p.client.SaveDashboard(ctx, dashboard, p.config.FolderUID)
+	if err != nil {
+		return "", err
+	}
+	return p.client.DashboardURL(result.URL), nil
+}
+
+// Complete applies the configured completion action to the experiment dashboard
+func (p *Provisioner) Complete(ctx context.Context, experimentID string) error {
+	uid := dashboardUID(experimentID)
+
+	switch p.config.OnComplete {
+	case OnCompleteDelete:
+		return p.client.DeleteDashboard(ctx, uid)
+
+	case OnCompleteArchive:
+		dashboard, err := p.client.GetDashboard(ctx, uid)
+		if err != nil {
+			return err
+		}
+		tags, _ := dashboard["tags"].([]interface{})
+		dashboard["tags"] = append(tags, "archived")
+		_, err = p.client.SaveDashboard(ctx, dashboard, p.config.ArchiveFolderUID)
+		return err
+
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,160 @@
+// Package guardrails watches the KPIs of running experiments and reports
+// candidates that degrade past a configured bound for long enough to abort
+// the experiment.
+package guardrails
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/phoenix/platform/pkg/promclient"
+	"github.com/phoenix/platform/pkg/semconv"
+)
+
+// Bound is the side of the threshold a KPI must stay on
+type Bound int
+
+const (
+	// Floor guardrails are violated when the KPI drops below the threshold
+	Floor Bound = iota
+	// Ceiling guardrails are violated when the KPI rises above the threshold
+	Ceiling
+)
+
+// Guardrail bounds one KPI of every candidate variant of an experiment
+type Guardrail struct {
+	Name string
+	// Series is the recording rule evaluated per variant, e.g.
+	// semconv.RuleSignalPreservation
+	Series    string
+	Bound     Bound
+	Threshold float64
+	// Sustained is how long the bound must be crossed before the guardrail
+	// is violated; zero aborts on the first bad evaluation
+	Sustained time.Duration
+}
+
+func (g Guardrail) crossed(value float64) bool {
+	if g.Bound == Ceiling {
+		return value > g.Threshold
+	}
+	return value < g.Threshold
+}
+
+// Violation is a guardrail a candidate has crossed for at least Sustained
+type Violation struct {
+	Guardrail string
+	Variant   string
+	Value     float64
+	Threshold float64
+	Since     time.Time
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("variant %s at %.4g against a threshold of %.4g since %s",
+		v.Variant, v.Value, v.Threshold, v.Since.UTC().Format(time.RFC3339))
+}
+
+// Querier runs instant PromQL queries, usually a *promclient.Client
+type Querier interface {
+	Query(ctx context.Context, name, query string) ([]promclient.Sample, error)
+}
+
+// Monitor evaluates guardrails and remembers since when each one has been
+// crossed, per experiment, guardrail and variant
+type Monitor struct {
+	querier Querier
+	now     func() time.Time
+
+	mu      sync.Mutex
+	crossed map[string]map[string]time.Time
+}
+
+func NewMonitor(querier Querier) *Monitor {
+	return &Monitor{
+		querier: querier,
+		now:     time.Now,
+		crossed: make(map[string]map[string]time.Time),
+	}
+}
+
+// Evaluate queries every guardrail for the given variants of an experiment,
+// usually its candidates, and returns the ones violated. Variants without
+// data are not judged.
+func (m *Monitor) Evaluate(ctx context.Context, experimentID string, variants []string, guardrails []Guardrail) ([]Violation, error) {
+	judged := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		judged[v] = true
+	}
+
+	now := m.now()
+	current := make(map[string]time.Time)
+	var violations []Violation
+
+	m.mu.Lock()
+	previous := m.crossed[experimentID]
+	m.mu.Unlock()
+
+	for _, g := range guardrails {
+		samples, err := m.querier.Query(ctx, g.Series, semconv.Selector(g.Series, semconv.Eq(semconv.LabelExperimentID, experimentID)))
+		if err != nil {
+			return nil, fmt.Errorf("guardrail %s: %w", g.Name, err)
+		}
+
+		for _, sample := range samples {
+			variant := sample.Labels[semconv.LabelVariant]
+			if !judged[variant] || math.IsNaN(sample.Value) || !g.crossed(sample.Value) {
+				continue
+			}
+
+			key := g.Name + "/" + variant
+			since, ok := previous[key]
+			if !ok {
+				since = now
+			}
+			current[key] = since
+
+			if now.Sub(since) >= g.Sustained {
+				violations = append(violations, Violation{
+					Guardrail: g.Name,
+					Variant:   variant,
+					Value:     sample.Value,
+					Threshold: g.Threshold,
+					Since:     since,
+				})
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.crossed[experimentID] = current
+	m.mu.Unlock()
+	return violations, nil
+}
+
+// Forget drops the state kept for an experiment that is no longer running
+func (m *Monitor) Forget(experimentID string) {
+	m.mu.Lock()
+	delete(m.crossed, experimentID)
+	m.mu.Unlock()
+}
+
+// Retain drops the state kept for every experiment not listed, e.g. the ones
+// that stopped running since the last evaluation
+func (m *Monitor) Retain(experimentIDs []string) {
+	keep := make(map[string]bool, len(experimentIDs))
+	for _, id := range experimentIDs {
+		keep[id] = true
+	}
+
+	m.mu.Lock()
+	for id := range m.crossed {
+		if !keep[id] {
+			delete(m.crossed, id)
+		}
+	}
+	m.mu.Unlock()
+}
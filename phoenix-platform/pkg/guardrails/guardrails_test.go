@@ -0,0 +1,188 @@
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/phoenix/platform/pkg/promclient"
+	"github.com/phoenix/platform/pkg/semconv"
+)
+
+func TestEvaluate(t *testing.T) {
+	preservation := Guardrail{Name: "signal_preservation", Series: "preservation", Bound: Floor, Threshold: 0.95}
+	latency := Guardrail{Name: "latency", Series: "latency", Bound: Ceiling, Threshold: 200}
+
+	tests := []struct {
+		name       string
+		guardrails []Guardrail
+		values     map[string]map[string]float64
+		want       []string
+	}{
+		{
+			name:       "floor crossed",
+			guardrails: []Guardrail{preservation},
+			values:     map[string]map[string]float64{"preservation": {"candidate": 0.9}},
+			want:       []string{"signal_preservation/candidate"},
+		},
+		{
+			name:       "floor held",
+			guardrails: []Guardrail{preservation},
+			values:     map[string]map[string]float64{"preservation": {"candidate": 0.95}},
+		},
+		{
+			name:       "ceiling crossed",
+			guardrails: []Guardrail{latency},
+			values:     map[string]map[string]float64{"latency": {"candidate": 250}},
+			want:       []string{"latency/candidate"},
+		},
+		{
+			name:       "ceiling held",
+			guardrails: []Guardrail{latency},
+			values:     map[string]map[string]float64{"latency": {"candidate": 150}},
+		},
+		{
+			name:       "variants not listed are not judged",
+			guardrails: []Guardrail{preservation},
+			values:     map[string]map[string]float64{"preservation": {"baseline": 0.5}},
+		},
+		{
+			name:       "nan is not judged",
+			guardrails: []Guardrail{preservation},
+			values:     map[string]map[string]float64{"preservation": {"candidate": math.NaN()}},
+		},
+		{
+			name:       "every guardrail is evaluated",
+			guardrails: []Guardrail{preservation, latency},
+			values: map[string]map[string]float64{
+				"preservation": {"candidate": 0.5},
+				"latency":      {"candidate": 500},
+			},
+			want: []string{"signal_preservation/candidate", "latency/candidate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMonitor(fakeQuerier(tt.values))
+			violations, err := m.Evaluate(context.Background(), "exp-1", []string{"candidate"}, tt.guardrails)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Guardrail+"/"+v.Variant)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("violations = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("violations = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateSustained(t *testing.T) {
+	g := Guardrail{Name: "signal_preservation", Series: "preservation", Bound: Floor, Threshold: 0.95, Sustained: 5 * time.Minute}
+	values := map[string]map[string]float64{"preservation": {"candidate": 0.9}}
+
+	m := NewMonitor(fakeQuerier(values))
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	m.now = func() time.Time { return now }
+
+	evaluate := func() []Violation {
+		t.Helper()
+		violations, err := m.Evaluate(context.Background(), "exp-1", []string{"candidate"}, []Guardrail{g})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return violations
+	}
+
+	if v := evaluate(); len(v) != 0 {
+		t.Fatalf("violated on the first crossing: %v", v)
+	}
+	now = start.Add(4 * time.Minute)
+	if v := evaluate(); len(v) != 0 {
+		t.Fatalf("violated before the bound was crossed for %s: %v", g.Sustained, v)
+	}
+	now = start.Add(5 * time.Minute)
+	v := evaluate()
+	if len(v) != 1 || !v[0].Since.Equal(start) {
+		t.Fatalf("violations = %v, want one since %s", v, start)
+	}
+
+	// Recovering resets the clock
+	values["preservation"]["candidate"] = 0.99
+	now = start.Add(6 * time.Minute)
+	if v := evaluate(); len(v) != 0 {
+		t.Fatalf("violated while the bound held: %v", v)
+	}
+	values["preservation"]["candidate"] = 0.9
+	now = start.Add(7 * time.Minute)
+	if v := evaluate(); len(v) != 0 {
+		t.Fatalf("violated right after recovering: %v", v)
+	}
+
+	// Forgetting the experiment resets the clock as well
+	now = start.Add(12 * time.Minute)
+	m.Forget("exp-1")
+	if v := evaluate(); len(v) != 0 {
+		t.Fatalf("violated after the experiment was forgotten: %v", v)
+	}
+}
+
+func TestRetain(t *testing.T) {
+	g := Guardrail{Name: "signal_preservation", Series: "preservation", Bound: Floor, Threshold: 0.95, Sustained: time.Minute}
+	m := NewMonitor(fakeQuerier(map[string]map[string]float64{"preservation": {"candidate": 0.9}}))
+
+	for _, id := range []string{"exp-1", "exp-2"} {
+		if _, err := m.Evaluate(context.Background(), id, []string{"candidate"}, []Guardrail{g}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Retain([]string{"exp-2"})
+
+	if _, ok := m.crossed["exp-1"]; ok {
+		t.Error("state of exp-1 was kept")
+	}
+	if _, ok := m.crossed["exp-2"]; !ok {
+		t.Error("state of exp-2 was dropped")
+	}
+}
+
+func TestEvaluateQueryError(t *testing.T) {
+	m := NewMonitor(failingQuerier{})
+	g := Guardrail{Name: "signal_preservation", Series: "preservation", Threshold: 0.95}
+	if _, err := m.Evaluate(context.Background(), "exp-1", []string{"candidate"}, []Guardrail{g}); err == nil {
+		t.Fatal("query error was swallowed")
+	}
+}
+
+// fakeQuerier serves one sample per variant of each series, keyed by series
+// name
+type fakeQuerier map[string]map[string]float64
+
+func (f fakeQuerier) Query(ctx context.Context, name, query string) ([]promclient.Sample, error) {
+	var samples []promclient.Sample
+	for variant, value := range f[name] {
+		samples = append(samples, promclient.Sample{
+			Labels: map[string]string{semconv.LabelExperimentID: "exp-1", semconv.LabelVariant: variant},
+			Value:  value,
+		})
+	}
+	return samples, nil
+}
+
+type failingQuerier struct{}
+
+func (failingQuerier) Query(ctx context.Context, name, query string) ([]promclient.Sample, error) {
+	return nil, errors.New("prometheus unavailable")
+}
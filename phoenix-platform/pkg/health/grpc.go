@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Names returns the registered dependency names in order
+func (c *Checker) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.probes))
+	for name := range c.probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeGRPC runs the probes every interval and publishes the results on a
+// grpc.health.v1 server: one service per dependency, plus the overall status
+// under the empty service name. When ctx is cancelled every service is set
+// to NOT_SERVING so clients stop sending new calls during shutdown.
+func (c *Checker) ServeGRPC(ctx context.Context, srv *grpchealth.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report := c.Check(ctx)
+		for name, result := range report.Dependencies {
+			srv.SetServingStatus(name, servingStatus(result.Status))
+		}
+		srv.SetServingStatus("", servingStatus(report.Status))
+
+		select {
+		case <-ctx.Done():
+			srv.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func servingStatus(status string) healthpb.HealthCheckResponse_ServingStatus {
+	if status == StatusOK {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// GRPC asks a grpc.health.v1 server for the status of one service; the empty
+// name is the overall status of the server
+func GRPC(conn grpc.ClientConnInterface, service string) Probe {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("status %s", resp.Status)
+		}
+		return nil
+	}
+}
+
+// GitRemote checks that a Git repository served over HTTP(S) answers the
+// smart protocol ref advertisement, authenticating with token when set
+func GitRemote(client *http.Client, repoURL, token string) Probe {
+	target := strings.TrimSuffix(repoURL, "/") + "/info/refs?service=git-upload-pack"
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+		if token != "" {
+			req.SetBasicAuth("x-access-token", token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// grpcHealthPrefix is the method prefix of the grpc.health.v1 service
+var grpcHealthPrefix = "/" + healthpb.Health_ServiceDesc.ServiceName + "/"
+
+// UnaryExempt skips interceptor, e.g. authentication or rate limiting, for
+// health checks so orchestrators can probe without credentials
+func UnaryExempt(interceptor grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, grpcHealthPrefix) {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+// StreamExempt is UnaryExempt for streaming calls such as Health/Watch
+func StreamExempt(interceptor grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, grpcHealthPrefix) {
+			return handler(srv, ss)
+		}
+		return interceptor(srv, ss, info, handler)
+	}
+}
@@ -0,0 +1,188 @@
+// Package health serves the /healthz (liveness) and /readyz (readiness)
+// endpoints shared by Phoenix services. Readiness runs every registered
+// dependency probe and reports each one in the response body.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Status values reported for the service and each dependency
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Probe checks that a dependency is reachable
+type Probe func(ctx context.Context) error
+
+// Result is the outcome of one probe
+type Result struct {
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Report is the body of /readyz
+type Report struct {
+	Status       string            `json:"status"`
+	Service      string            `json:"service"`
+	Version      string            `json:"version,omitempty"`
+	Dependencies map[string]Result `json:"dependencies,omitempty"`
+}
+
+// Checker runs dependency probes for a service
+type Checker struct {
+	service string
+	version string
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	probes map[string]Probe
+}
+
+// New creates a Checker. Every probe gets timeout to answer.
+func New(service, version string, timeout time.Duration) *Checker {
+	return &Checker{
+		service: service,
+		version: version,
+		timeout: timeout,
+		probes:  map[string]Probe{},
+	}
+}
+
+// Register adds a readiness probe under the given dependency name
+func (c *Checker) Register(name string, probe Probe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probes[name] = probe
+}
+
+// Check runs every probe concurrently
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	probes := make(map[string]Probe, len(c.probes))
+	for name, probe := range c.probes {
+		probes[name] = probe
+	}
+	c.mu.RUnlock()
+
+	report := Report{
+		Status:       StatusOK,
+		Service:      c.service,
+		Version:      c.version,
+		Dependencies: make(map[string]Result, len(probes)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, probe := range probes {
+		wg.Add(1)
+		go func(name string, probe Probe) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := probe(ctx)
+			result := Result{
+				Status:     StatusOK,
+				DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = StatusUnavailable
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Dependencies[name] = result
+			if err != nil {
+				report.Status = StatusUnavailable
+			}
+			mu.Unlock()
+		}(name, probe)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// LivenessHandler answers 200 as long as the process can serve requests
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: StatusOK, Service: c.service, Version: c.version})
+	})
+}
+
+// ReadinessHandler answers 200 when every dependency probe passes and 503
+// otherwise
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		code := http.StatusOK
+		if report.Status != StatusOK {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, report)
+	})
+}
+
+// Mux is satisfied by http.ServeMux and chi routers
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// Mount registers /healthz and /readyz on mux
+func (c *Checker) Mount(mux Mux) {
+	mux.Handle("/healthz", c.LivenessHandler())
+	mux.Handle("/readyz", c.ReadinessHandler())
+}
+
+// Paths are the endpoints served by Mount, e.g. for rate limit exemptions
+var Paths = []string{"/healthz", "/readyz"}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Database pings the database
+func Database(db *sql.DB) Probe {
+	return db.PingContext
+}
+
+// HTTP expects a 2xx answer to a GET of the URL
+func HTTP(client *http.Client, target string) Probe {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// Prometheus runs a trivial instant query, which also proves the query
+// engine (not just the web server) is up
+func Prometheus(client *http.Client, baseURL string) Probe {
+	return HTTP(client, baseURL+"/api/v1/query?query="+url.QueryEscape("vector(1)"))
+}
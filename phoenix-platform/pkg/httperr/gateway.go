@@ -0,0 +1,24 @@
+package httperr
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+)
+
+// GatewayErrorHandler renders grpc-gateway errors as problem+json
+func GatewayErrorHandler(logger *zap.Logger) runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		p := FromGRPC(err)
+		if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+			if retryAfter := md.HeaderMD.Get("retry-after"); len(retryAfter) > 0 {
+				w.Header().Set("Retry-After", retryAfter[0])
+			}
+		}
+
+		Log(logger, r, p)
+		Write(w, r, p)
+	}
+}
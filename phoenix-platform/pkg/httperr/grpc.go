@@ -0,0 +1,36 @@
+package httperr
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var grpcCodes = map[codes.Code]Code{
+	codes.InvalidArgument:    CodeValidation,
+	codes.OutOfRange:         CodeValidation,
+	codes.FailedPrecondition: CodeConflict,
+	codes.AlreadyExists:      CodeConflict,
+	codes.Aborted:            CodeConflict,
+	codes.Unauthenticated:    CodeUnauthenticated,
+	codes.PermissionDenied:   CodeForbidden,
+	codes.NotFound:           CodeNotFound,
+	codes.ResourceExhausted:  CodeRateLimited,
+	codes.Unavailable:        CodeUpstreamUnavailable,
+	codes.DeadlineExceeded:   CodeTimeout,
+	codes.Unimplemented:      CodeNotImplemented,
+}
+
+// FromGRPC converts a gRPC status error into a problem, keeping the status
+// message as the detail for client errors
+func FromGRPC(err error) *Problem {
+	st, ok := status.FromError(err)
+	if !ok {
+		return From(err)
+	}
+
+	code, ok := grpcCodes[st.Code()]
+	if !ok {
+		return Internal("an internal error occurred")
+	}
+	return New(code, "%s", st.Message())
+}
@@ -0,0 +1,170 @@
+// Package httperr writes RFC 7807 problem+json error responses so every
+// Phoenix HTTP service reports errors the same way.
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// ContentType is the media type of problem responses
+const ContentType = "application/problem+json"
+
+// typeBase prefixes the problem type URI of every code
+const typeBase = "https://phoenix.io/problems/"
+
+// Code classifies an error independently of the HTTP status
+type Code string
+
+const (
+	CodeValidation          Code = "validation"
+	CodeUnauthenticated     Code = "unauthenticated"
+	CodeForbidden           Code = "forbidden"
+	CodeNotFound            Code = "not_found"
+	CodeMethodNotAllowed    Code = "method_not_allowed"
+	CodeConflict            Code = "conflict"
+	CodeRateLimited         Code = "rate_limited"
+	CodeUpstreamUnavailable Code = "upstream_unavailable"
+	CodeTimeout             Code = "timeout"
+	CodeNotImplemented      Code = "not_implemented"
+	CodeInternal            Code = "internal"
+)
+
+var codeStatus = map[Code]int{
+	CodeValidation:          http.StatusBadRequest,
+	CodeUnauthenticated:     http.StatusUnauthorized,
+	CodeForbidden:           http.StatusForbidden,
+	CodeNotFound:            http.StatusNotFound,
+	CodeMethodNotAllowed:    http.StatusMethodNotAllowed,
+	CodeConflict:            http.StatusConflict,
+	CodeRateLimited:         http.StatusTooManyRequests,
+	CodeUpstreamUnavailable: http.StatusServiceUnavailable,
+	CodeTimeout:             http.StatusGatewayTimeout,
+	CodeNotImplemented:      http.StatusNotImplemented,
+	CodeInternal:            http.StatusInternalServerError,
+}
+
+// Status returns the HTTP status for a code
+func (c Code) Status() int {
+	if status, ok := codeStatus[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError describes one invalid input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details object. It implements error so
+// handlers can return it and let Write pick it up.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// New creates a problem for a code with a human readable detail
+func New(code Code, format string, args ...interface{}) *Problem {
+	status := code.Status()
+	return &Problem{
+		Type:   typeBase + string(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: fmt.Sprintf(format, args...),
+		Code:   code,
+	}
+}
+
+func (p *Problem) Error() string {
+	return fmt.Sprintf("%s: %s", p.Code, p.Detail)
+}
+
+// WithFields attaches per-field validation errors
+func (p *Problem) WithFields(fields ...FieldError) *Problem {
+	p.Errors = append(p.Errors, fields...)
+	return p
+}
+
+func Validation(format string, args ...interface{}) *Problem {
+	return New(CodeValidation, format, args...)
+}
+
+func NotFound(format string, args ...interface{}) *Problem {
+	return New(CodeNotFound, format, args...)
+}
+
+func RateLimited(format string, args ...interface{}) *Problem {
+	return New(CodeRateLimited, format, args...)
+}
+
+func UpstreamUnavailable(format string, args ...interface{}) *Problem {
+	return New(CodeUpstreamUnavailable, format, args...)
+}
+
+func Internal(format string, args ...interface{}) *Problem {
+	return New(CodeInternal, format, args...)
+}
+
+// From converts any error to a problem. Errors that are not problems become
+// internal errors whose detail does not leak the underlying message.
+func From(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+	return Internal("an internal error occurred")
+}
+
+// Write sends err as a problem+json response
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := From(err)
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = middleware.GetReqID(r.Context())
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Log records an error with the fields every service uses for HTTP errors.
+// Server-side failures are logged at error level, client errors at info.
+func Log(logger *zap.Logger, r *http.Request, err error) {
+	p := From(err)
+	fields := []zap.Field{
+		zap.String("code", string(p.Code)),
+		zap.Int("status", p.Status),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.String("request_id", middleware.GetReqID(r.Context())),
+		zap.Error(err),
+	}
+
+	if p.Status >= http.StatusInternalServerError {
+		logger.Error("request failed", fields...)
+		return
+	}
+	logger.Info("request rejected", fields...)
+}
+
+// Respond logs err and writes it as a problem+json response
+func Respond(logger *zap.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	Log(logger, r, err)
+	Write(w, r, err)
+}
@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/phoenix/platform/pkg/eventbus"
+)
+
+// anomalyConsumer names the anomaly forwarder in delivery claims
+const anomalyConsumer = "notifications.anomalies"
+
+// ForwardAnomalies notifies every AnomalyDetected event published on the bus
+// until ctx is cancelled. dashboardURL, when set, is linked from each message.
+// When the bus is shared by several replicas, claimer makes sure only one of
+// them sends each notification; it may be nil for a single process.
+func (n *Notifier) ForwardAnomalies(ctx context.Context, bus eventbus.Bus, claimer eventbus.Claimer, dashboardURL string, logger *zap.Logger) error {
+	handler := func(ctx context.Context, event eventbus.Event, anomaly eventbus.AnomalyDetected) error {
+		return n.Notify(ctx, AnomalyNotification(event, anomaly, dashboardURL))
+	}
+	if claimer != nil {
+		handler = eventbus.Once(claimer, anomalyConsumer, handler)
+	}
+	return eventbus.Handle(ctx, bus, logger, handler)
+}
+
+// AnomalyNotification describes a detected anomaly
+func AnomalyNotification(event eventbus.Event, anomaly eventbus.AnomalyDetected, dashboardURL string) *Notification {
+	fields := []Field{
+		{Name: "Metric", Value: anomaly.Metric},
+		{Name: "Value", Value: fmt.Sprintf("%g", anomaly.Value)},
+		{Name: "Threshold", Value: fmt.Sprintf("%g", anomaly.Threshold)},
+	}
+	labels := make([]string, 0, len(anomaly.Labels))
+	for k := range anomaly.Labels {
+		labels = append(labels, k)
+	}
+	sort.Strings(labels)
+	for _, k := range labels {
+		fields = append(fields, Field{Name: k, Value: anomaly.Labels[k]})
+	}
+
+	return &Notification{
+		Title:    fmt.Sprintf("Anomaly detected on %s", anomaly.Metric),
+		Text:     fmt.Sprintf("%s reported %s at %g (threshold %g).", anomaly.Detector, anomaly.Metric, anomaly.Value, anomaly.Threshold),
+		Severity: ParseSeverity(anomaly.Severity),
+		Source:   event.Source,
+		URL:      dashboardURL,
+		Fields:   fields,
+		Time:     event.Time,
+	}
+}
@@ -0,0 +1,114 @@
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SlackChannel posts to a Slack incoming webhook
+type SlackChannel struct {
+	url    string
+	client *http.Client
+}
+
+func NewSlackChannel(webhookURL string) *SlackChannel {
+	return &SlackChannel{url: webhookURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *SlackChannel) Name() string {
+	return "slack"
+}
+
+func (c *SlackChannel) Send(ctx context.Context, n *Notification) error {
+	fields := make([]map[string]interface{}, 0, len(n.Fields))
+	for _, f := range n.Fields {
+		fields = append(fields, map[string]interface{}{"title": f.Name, "value": f.Value, "short": true})
+	}
+
+	attachment := map[string]interface{}{
+		"fallback": n.Title,
+		"color":    n.Severity.Color(),
+		"title":    n.Title,
+		"text":     n.Text,
+		"fields":   fields,
+		"footer":   n.Source,
+		"ts":       n.Time.Unix(),
+	}
+	if n.URL != "" {
+		attachment["title_link"] = n.URL
+	}
+
+	return postJSON(ctx, c.client, c.url, map[string]interface{}{
+		"attachments": []interface{}{attachment},
+	}, nil)
+}
+
+// TeamsChannel posts a MessageCard to a Microsoft Teams incoming webhook
+type TeamsChannel struct {
+	url    string
+	client *http.Client
+}
+
+func NewTeamsChannel(webhookURL string) *TeamsChannel {
+	return &TeamsChannel{url: webhookURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *TeamsChannel) Name() string {
+	return "teams"
+}
+
+func (c *TeamsChannel) Send(ctx context.Context, n *Notification) error {
+	facts := make([]map[string]string, 0, len(n.Fields))
+	for _, f := range n.Fields {
+		facts = append(facts, map[string]string{"name": f.Name, "value": f.Value})
+	}
+
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    n.Title,
+		"themeColor": strings.TrimPrefix(n.Severity.Color(), "#"),
+		"title":      n.Title,
+		"sections": []interface{}{map[string]interface{}{
+			"activitySubtitle": n.Source,
+			"text":             n.Text,
+			"facts":            facts,
+		}},
+	}
+	if n.URL != "" {
+		card["potentialAction"] = []interface{}{map[string]interface{}{
+			"@type":   "OpenUri",
+			"name":    "Open dashboard",
+			"targets": []map[string]string{{"os": "default", "uri": n.URL}},
+		}}
+	}
+
+	return postJSON(ctx, c.client, c.url, card, nil)
+}
+
+// WebhookChannel posts the notification as JSON to an arbitrary endpoint
+type WebhookChannel struct {
+	url    string
+	header map[string]string
+	client *http.Client
+}
+
+// NewWebhookChannel creates a generic webhook channel. authHeader, when set,
+// is sent verbatim as the Authorization header.
+func NewWebhookChannel(url, authHeader string) *WebhookChannel {
+	c := &WebhookChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+	if authHeader != "" {
+		c.header = map[string]string{"Authorization": authHeader}
+	}
+	return c
+}
+
+func (c *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, n *Notification) error {
+	return postJSON(ctx, c.client, c.url, n, c.header)
+}
@@ -0,0 +1,287 @@
+// Package notifications delivers anomaly alerts and experiment verdicts to
+// chat and webhook channels, routed by severity and rate limited per channel.
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/phoenix/platform/pkg/semconv"
+)
+
+var notificationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: semconv.NotificationsTotal,
+	Help: "Notifications handled per channel, by result (sent, failed, suppressed)",
+}, []string{semconv.LabelChannel, semconv.LabelResult})
+
+// Severity orders notifications for routing
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// ParseSeverity maps free-form severities reported by detectors onto the
+// three routing levels. Unknown values are treated as warnings.
+func ParseSeverity(s string) Severity {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "info", "low", "ok":
+		return SeverityInfo
+	case "critical", "high", "error", "page":
+		return SeverityCritical
+	default:
+		return SeverityWarning
+	}
+}
+
+// Color is the hex color used for the severity in chat cards
+func (s Severity) Color() string {
+	switch s {
+	case SeverityInfo:
+		return "#2EB67D"
+	case SeverityCritical:
+		return "#E01E5A"
+	default:
+		return "#ECB22E"
+	}
+}
+
+// Field is a key/value pair rendered next to the message text
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Notification is a single message sent to one or more channels
+type Notification struct {
+	Title    string    `json:"title"`
+	Text     string    `json:"text"`
+	Severity Severity  `json:"severity"`
+	Source   string    `json:"source"`
+	URL      string    `json:"url,omitempty"`
+	Fields   []Field   `json:"fields,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Channel delivers a rendered notification
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, n *Notification) error
+}
+
+// Config selects the channels and routing rules of a Notifier
+type Config struct {
+	SlackWebhookURL   string
+	TeamsWebhookURL   string
+	WebhookURL        string
+	WebhookAuthHeader string
+
+	// Routes maps a severity to the channel names ("slack", "teams",
+	// "webhook") it is sent to. Severities without a route go to every
+	// configured channel.
+	Routes map[Severity][]string
+
+	// RatePerMinute caps the notifications sent to each channel. Zero
+	// disables the limit.
+	RatePerMinute int
+
+	// Template renders the message text from a Notification. The default
+	// template uses the notification text unchanged.
+	Template string
+}
+
+// Notifier routes notifications to channels
+type Notifier struct {
+	channels map[string]Channel
+	routes   map[Severity][]string
+	template *template.Template
+	limiters map[string]*rate.Limiter
+	logger   *zap.Logger
+
+	mu         sync.Mutex
+	suppressed map[string]int
+}
+
+// New builds a Notifier from cfg. It returns nil when no channel is configured.
+func New(cfg Config, logger *zap.Logger) (*Notifier, error) {
+	channels := map[string]Channel{}
+	if cfg.SlackWebhookURL != "" {
+		channels["slack"] = NewSlackChannel(cfg.SlackWebhookURL)
+	}
+	if cfg.TeamsWebhookURL != "" {
+		channels["teams"] = NewTeamsChannel(cfg.TeamsWebhookURL)
+	}
+	if cfg.WebhookURL != "" {
+		channels["webhook"] = NewWebhookChannel(cfg.WebhookURL, cfg.WebhookAuthHeader)
+	}
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	return NewWithChannels(channels, cfg, logger)
+}
+
+// NewWithChannels builds a Notifier around pre-built channels
+func NewWithChannels(channels map[string]Channel, cfg Config, logger *zap.Logger) (*Notifier, error) {
+	for severity, names := range cfg.Routes {
+		for _, name := range names {
+			if _, ok := channels[name]; !ok {
+				return nil, fmt.Errorf("route for %s severity references unconfigured channel %q", severity, name)
+			}
+		}
+	}
+
+	text := cfg.Template
+	if text == "" {
+		text = "{{ .Text }}"
+	}
+	tmpl, err := template.New("notification").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification template: %w", err)
+	}
+
+	limiters := map[string]*rate.Limiter{}
+	if cfg.RatePerMinute > 0 {
+		for name := range channels {
+			limiters[name] = rate.NewLimiter(rate.Every(time.Minute/time.Duration(cfg.RatePerMinute)), cfg.RatePerMinute)
+		}
+	}
+
+	return &Notifier{
+		channels:   channels,
+		routes:     cfg.Routes,
+		template:   tmpl,
+		limiters:   limiters,
+		logger:     logger,
+		suppressed: map[string]int{},
+	}, nil
+}
+
+// Notify renders n and sends it to every channel routed for its severity.
+// Channels over their rate limit drop the notification; the next one that
+// gets through reports how many were suppressed.
+func (n *Notifier) Notify(ctx context.Context, notification *Notification) error {
+	if notification.Time.IsZero() {
+		notification.Time = time.Now().UTC()
+	}
+	if notification.Severity == "" {
+		notification.Severity = SeverityInfo
+	}
+
+	var text bytes.Buffer
+	if err := n.template.Execute(&text, notification); err != nil {
+		return fmt.Errorf("failed to render notification: %w", err)
+	}
+
+	var errs []error
+	for _, name := range n.route(notification.Severity) {
+		if limiter, ok := n.limiters[name]; ok && !limiter.Allow() {
+			n.mu.Lock()
+			n.suppressed[name]++
+			n.mu.Unlock()
+			notificationsTotal.WithLabelValues(name, "suppressed").Inc()
+			continue
+		}
+
+		rendered := *notification
+		rendered.Text = text.String()
+		n.mu.Lock()
+		if count := n.suppressed[name]; count > 0 {
+			rendered.Text += fmt.Sprintf("\n\n(%d earlier notifications were suppressed by rate limiting)", count)
+			n.suppressed[name] = 0
+		}
+		n.mu.Unlock()
+
+		if err := n.channels[name].Send(ctx, &rendered); err != nil {
+			notificationsTotal.WithLabelValues(name, "failed").Inc()
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		notificationsTotal.WithLabelValues(name, "sent").Inc()
+	}
+	return errors.Join(errs...)
+}
+
+// route returns the channel names a severity is delivered to
+func (n *Notifier) route(severity Severity) []string {
+	if names, ok := n.routes[severity]; ok {
+		return names
+	}
+	names := make([]string, 0, len(n.channels))
+	for name := range n.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseRoutes parses routing rules of the form
+// "critical=slack,teams;warning=slack;info=webhook"
+func ParseRoutes(s string) (map[Severity][]string, error) {
+	routes := map[Severity][]string{}
+	for _, rule := range strings.Split(s, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		severity, channels, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid route %q, expected <severity>=<channel>[,<channel>]", rule)
+		}
+		sev := Severity(strings.TrimSpace(severity))
+		switch sev {
+		case SeverityInfo, SeverityWarning, SeverityCritical:
+		default:
+			return nil, fmt.Errorf("invalid route %q: unknown severity %q", rule, severity)
+		}
+		routes[sev] = []string{}
+		for _, name := range strings.Split(channels, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				routes[sev] = append(routes[sev], name)
+			}
+		}
+	}
+	return routes, nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}, header map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+// Package promclient is the Prometheus query client shared by Phoenix
+// components. Several of them evaluate near-identical queries every few
+// seconds, so identical concurrent queries are coalesced into one request,
+// results are cached for a short TTL, and transient failures are retried
+// with jittered backoff.
+package promclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/phoenix/platform/pkg/semconv"
+)
+
+// Query results
+const (
+	resultOK        = "ok"
+	resultError     = "error"
+	resultCacheHit  = "cache_hit"
+	resultCoalesced = "coalesced"
+)
+
+var (
+	queriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: semconv.PromClientQueriesTotal,
+		Help: "Prometheus queries by calling client, query name and result",
+	}, []string{semconv.LabelClient, semconv.LabelQuery, semconv.LabelResult})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    semconv.PromClientQueryDuration,
+		Help:    "Time spent on Prometheus queries that were sent, retries included",
+		Buckets: prometheus.DefBuckets,
+	}, []string{semconv.LabelClient, semconv.LabelQuery})
+
+	retriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: semconv.PromClientRetriesTotal,
+		Help: "Prometheus query attempts that were retried",
+	}, []string{semconv.LabelClient, semconv.LabelQuery})
+)
+
+// Config configures a Client. Zero values use the defaults.
+type Config struct {
+	URL string
+	// Client identifies the caller in the client metrics, e.g. "phoenix-api"
+	Client string
+	// Timeout bounds each attempt; default 10s
+	Timeout time.Duration
+	// Retries is the number of extra attempts after network errors, 429 and
+	// 5xx responses; default 2, negative disables retries
+	Retries int
+	// Backoff is the base delay before the first retry, doubled for every
+	// further retry with full jitter; default 250ms
+	Backoff time.Duration
+	// CacheTTL is how long results are reused; default 15s, negative
+	// disables caching
+	CacheTTL time.Duration
+	// Concurrency bounds the queries QueryBatch runs at once; default 4
+	Concurrency int
+	HTTPClient  *http.Client
+}
+
+// Sample is one series of an instant query result
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Result is the outcome of one query of a batch
+type Result struct {
+	Samples []Sample
+	Err     error
+}
+
+// Request is one named query of a batch
+type Request struct {
+	// Name labels the client metrics; keep it low cardinality, e.g. the
+	// metric or recording rule queried
+	Name  string
+	Query string
+}
+
+// Client runs instant queries against one Prometheus server
+type Client struct {
+	cfg     Config
+	baseURL string
+	now     func() time.Time
+
+	mu       sync.Mutex
+	cache    map[string]cacheEntry
+	inflight map[string]*call
+}
+
+type cacheEntry struct {
+	samples []Sample
+	expires time.Time
+}
+
+// call is a query in flight that later identical queries wait for
+type call struct {
+	done    chan struct{}
+	samples []Sample
+	err     error
+}
+
+func New(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("prometheus URL is required")
+	}
+	if _, err := url.Parse(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid prometheus URL: %w", err)
+	}
+	if cfg.Client == "" {
+		cfg.Client = "unknown"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = 2
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 250 * time.Millisecond
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = 15 * time.Second
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{}
+	}
+
+	return &Client{
+		cfg:      cfg,
+		baseURL:  strings.TrimRight(cfg.URL, "/"),
+		now:      time.Now,
+		cache:    make(map[string]cacheEntry),
+		inflight: make(map[string]*call),
+	}, nil
+}
+
+// Query evaluates query at the current time. name labels the client metrics.
+// The returned samples are shared with other callers and must not be
+// modified. Coalescing and caching are keyed by the query text alone, so
+// callers passing the same query under different names share one request.
+// Cache hits and coalesced calls are counted under each caller's name, the
+// request itself under the name of the caller that sent it.
+func (c *Client) Query(ctx context.Context, name, query string) ([]Sample, error) {
+	c.mu.Lock()
+	if entry, ok := c.cache[query]; ok && c.now().Before(entry.expires) {
+		c.mu.Unlock()
+		queriesTotal.WithLabelValues(c.cfg.Client, name, resultCacheHit).Inc()
+		return entry.samples, nil
+	}
+	current, ok := c.inflight[query]
+	if ok {
+		queriesTotal.WithLabelValues(c.cfg.Client, name, resultCoalesced).Inc()
+	} else {
+		current = &call{done: make(chan struct{})}
+		c.inflight[query] = current
+		go c.run(name, query, current)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-current.done:
+		return current.samples, current.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run sends a query on behalf of every caller waiting for it. It is not tied
+// to any caller's context, so one caller giving up does not fail the others.
+func (c *Client) run(name, query string, current *call) {
+	start := time.Now()
+	current.samples, current.err = c.queryWithRetries(context.Background(), name, query)
+	queryDuration.WithLabelValues(c.cfg.Client, name).Observe(time.Since(start).Seconds())
+
+	if current.err != nil {
+		queriesTotal.WithLabelValues(c.cfg.Client, name, resultError).Inc()
+	} else {
+		queriesTotal.WithLabelValues(c.cfg.Client, name, resultOK).Inc()
+	}
+
+	c.mu.Lock()
+	delete(c.inflight, query)
+	if current.err == nil && c.cfg.CacheTTL > 0 {
+		c.store(query, current.samples)
+	}
+	c.mu.Unlock()
+	close(current.done)
+}
+
+// QueryBatch runs the queries concurrently, at most Concurrency at a time,
+// and returns their results in order
+func (c *Client) QueryBatch(ctx context.Context, requests []Request) []Result {
+	results := make([]Result, len(requests))
+	sem := make(chan struct{}, c.cfg.Concurrency)
+
+	var wg sync.WaitGroup
+	for i, r := range requests {
+		wg.Add(1)
+		go func(i int, r Request) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				return
+			}
+			results[i].Samples, results[i].Err = c.Query(ctx, r.Name, r.Query)
+		}(i, r)
+	}
+	wg.Wait()
+	return results
+}
+
+// store caches samples; expired entries are swept as the cache grows so
+// one-off queries do not accumulate. Callers hold c.mu.
+func (c *Client) store(query string, samples []Sample) {
+	now := c.now()
+	if len(c.cache) >= 1024 {
+		for q, entry := range c.cache {
+			if !now.Before(entry.expires) {
+				delete(c.cache, q)
+			}
+		}
+	}
+	c.cache[query] = cacheEntry{samples: samples, expires: now.Add(c.cfg.CacheTTL)}
+}
+
+func (c *Client) queryWithRetries(ctx context.Context, name, query string) ([]Sample, error) {
+	retries := c.cfg.Retries
+	if retries < 0 {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		samples, retryable, err := c.query(ctx, query)
+		if err == nil {
+			return samples, nil
+		}
+		if !retryable || attempt >= retries {
+			return nil, err
+		}
+
+		retriesTotal.WithLabelValues(c.cfg.Client, name).Inc()
+		backoff := c.cfg.Backoff << attempt
+		time.Sleep(time.Duration(rand.Int63n(int64(backoff) + 1)))
+	}
+}
+
+// query sends one attempt and reports whether a failure is worth retrying
+func (c *Client) query(ctx context.Context, query string) ([]Sample, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/query?query="+url.QueryEscape(query), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return nil, true, fmt.Errorf("prometheus returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Metric map[string]string `json:"metric"`
+				Value  [2]interface{}    `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, false, fmt.Errorf("failed to decode query response (status %d): %w", resp.StatusCode, err)
+	}
+	if body.Status != "success" {
+		return nil, false, fmt.Errorf("query failed: %s", body.Error)
+	}
+	if body.Data.ResultType != "vector" {
+		return nil, false, fmt.Errorf("expected a vector result, got %s", body.Data.ResultType)
+	}
+
+	samples := make([]Sample, 0, len(body.Data.Result))
+	for _, r := range body.Data.Result {
+		raw, ok := r.Value[1].(string)
+		if !ok {
+			return nil, false, fmt.Errorf("unexpected sample value %v", r.Value[1])
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, false, err
+		}
+		samples = append(samples, Sample{Labels: r.Metric, Value: value})
+	}
+	return samples, false, nil
+}
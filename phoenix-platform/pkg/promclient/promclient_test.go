@@ -0,0 +1,266 @@
+package promclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// vector answers an instant query with one sample labelled with the query
+func vector(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("query")
+	fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"query":%q},"value":[1700000000,"42"]}]}}`, query)
+}
+
+func newClient(t *testing.T, cfg Config) *Client {
+	t.Helper()
+	cfg.Client = t.Name()
+	cfg.Backoff = time.Millisecond
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestQueryCoalescesIdenticalQueries(t *testing.T) {
+	var requests atomic.Int32
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		received <- struct{}{}
+		<-release
+		vector(w, r)
+	}))
+	defer server.Close()
+	c := newClient(t, Config{URL: server.URL})
+
+	const callers = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	query := func(name string) {
+		defer wg.Done()
+		samples, err := c.Query(context.Background(), name, "up")
+		if err == nil && (len(samples) != 1 || samples[0].Value != 42) {
+			err = fmt.Errorf("samples = %+v", samples)
+		}
+		errs <- err
+	}
+
+	before := coalesced(t, callers-1)
+	wg.Add(1)
+	go query("first")
+	<-received
+	// Later callers join the request in flight, whatever name they use
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go query(fmt.Sprintf("caller-%d", i))
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for coalesced(t, callers-1)-before < callers-1 {
+		if time.Now().After(deadline) {
+			t.Fatal("callers did not join the query in flight")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("sent %d requests, want 1", n)
+	}
+}
+
+// coalesced sums the coalesced calls counted for caller-1..caller-n
+func coalesced(t *testing.T, n int) int {
+	total := 0.0
+	for i := 1; i <= n; i++ {
+		total += testutil.ToFloat64(queriesTotal.WithLabelValues(t.Name(), fmt.Sprintf("caller-%d", i), resultCoalesced))
+	}
+	return int(total)
+}
+
+func TestQueryCachesUntilTTL(t *testing.T) {
+	var requests atomic.Int32
+	fail := atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if fail.Load() {
+			http.Error(w, `{"status":"error","error":"bad query"}`, http.StatusBadRequest)
+			return
+		}
+		vector(w, r)
+	}))
+	defer server.Close()
+	c := newClient(t, Config{URL: server.URL, CacheTTL: time.Minute})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := c.Query(ctx, "up", "up"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("sent %d requests within the TTL, want 1", n)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := c.Query(ctx, "up", "up"); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("sent %d requests after the TTL, want 2", n)
+	}
+
+	// Failures are not cached
+	fail.Store(true)
+	for i := 0; i < 2; i++ {
+		if _, err := c.Query(ctx, "other", "other"); err == nil {
+			t.Fatal("failed query returned no error")
+		}
+	}
+	if n := requests.Load(); n != 4 {
+		t.Errorf("sent %d requests, want a failed query to be sent again", n)
+	}
+}
+
+func TestQueryRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		retries  int
+		wantErr  bool
+		attempts int32
+	}{
+		{"recovers after 5xx and 429", []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, 2, false, 3},
+		{"gives up after retries", []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK}, 2, true, 3},
+		{"does not retry client errors", []int{http.StatusBadRequest, http.StatusOK}, 2, true, 1},
+		{"negative disables retries", []int{http.StatusServiceUnavailable, http.StatusOK}, -1, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[attempts.Add(1)-1]
+				if status != http.StatusOK {
+					http.Error(w, `{"status":"error","error":"unavailable"}`, status)
+					return
+				}
+				vector(w, r)
+			}))
+			defer server.Close()
+			c := newClient(t, Config{URL: server.URL, Retries: tt.retries})
+
+			_, err := c.Query(context.Background(), "up", "up")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if n := attempts.Load(); n != tt.attempts {
+				t.Errorf("made %d attempts, want %d", n, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestQueryRetryBackoffIsJittered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	c, err := New(Config{URL: server.URL, Client: t.Name(), Retries: 3, Backoff: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retries := retriesTotal.WithLabelValues(t.Name(), "up")
+	before := testutil.ToFloat64(retries)
+
+	// Full jitter waits at most 20+40+80ms over three retries; without
+	// jitter every run would take exactly that long
+	var fastest time.Duration
+	for i := 0; i < 5; i++ {
+		c.cache = make(map[string]cacheEntry)
+		start := time.Now()
+		if _, err := c.Query(context.Background(), "up", "up"); err == nil {
+			t.Fatal("query against a failing server succeeded")
+		}
+		if elapsed := time.Since(start); i == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+	if fastest >= 140*time.Millisecond {
+		t.Errorf("fastest of five runs took %s, want the jittered backoff below 140ms", fastest)
+	}
+	if got := testutil.ToFloat64(retries) - before; got != 15 {
+		t.Errorf("counted %v retries, want 15", got)
+	}
+}
+
+func TestQueryBatch(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if strings.Contains(r.URL.Query().Get("query"), "broken") {
+			http.Error(w, `{"status":"error","error":"parse error"}`, http.StatusBadRequest)
+			return
+		}
+		vector(w, r)
+	}))
+	defer server.Close()
+	c := newClient(t, Config{URL: server.URL, Concurrency: 2})
+
+	var requests []Request
+	for i := 0; i < 6; i++ {
+		requests = append(requests, Request{Name: "batch", Query: fmt.Sprintf("q%d", i)})
+	}
+	requests[3].Query = "broken"
+
+	results := c.QueryBatch(context.Background(), requests)
+	if len(results) != len(requests) {
+		t.Fatalf("got %d results, want %d", len(results), len(requests))
+	}
+	for i, r := range results {
+		if i == 3 {
+			if r.Err == nil {
+				t.Error("broken query returned no error")
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("%s: %v", requests[i].Query, r.Err)
+			continue
+		}
+		if got := r.Samples[0].Labels["query"]; got != requests[i].Query {
+			t.Errorf("result %d is for %q, want %q", i, got, requests[i].Query)
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("%d queries ran at once, want at most 2", p)
+	}
+}
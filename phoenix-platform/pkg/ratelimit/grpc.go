@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// gatewayHeader carries the per-process secret of the in-process REST
+// gateway. The HTTP middleware has already limited those calls by the
+// client's IP; by peer address they would all share the loopback bucket.
+const gatewayHeader = "x-phoenix-gateway-secret"
+
+// UnaryInterceptor limits calls by client IP. Install it ahead of
+// authentication so unauthenticated callers are limited too.
+func (l *Limiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.checkIP(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor limits stream creation by client IP
+func (l *Limiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.checkIP(ss.Context()); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// CallerUnaryInterceptor limits calls by authenticated caller. Install it
+// after authentication; caller returns the verified identity from the
+// context, or "" for calls that need none.
+func (l *Limiter) CallerUnaryInterceptor(caller func(context.Context) string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.checkCaller(ctx, caller(ctx)); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// CallerStreamInterceptor limits stream creation by authenticated caller
+func (l *Limiter) CallerStreamInterceptor(caller func(context.Context) string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.checkCaller(ss.Context(), caller(ss.Context())); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// GatewayCredentials identifies the in-process REST gateway to the
+// interceptors; pass it to the gateway's dial options with
+// grpc.WithPerRPCCredentials
+func (l *Limiter) GatewayCredentials() credentials.PerRPCCredentials {
+	return l.gateway
+}
+
+func (l *Limiter) checkIP(ctx context.Context) error {
+	if l.gateway.verify(ctx) {
+		return nil
+	}
+
+	allowed, retryAfter := l.AllowIP(peerIP(ctx))
+	if allowed {
+		return nil
+	}
+	return rejectGRPC(ctx, KeyTypeIP, retryAfter)
+}
+
+func (l *Limiter) checkCaller(ctx context.Context, caller string) error {
+	if caller == "" {
+		return nil
+	}
+
+	allowed, retryAfter := l.AllowCaller(caller)
+	if allowed {
+		return nil
+	}
+	return rejectGRPC(ctx, KeyTypeToken, retryAfter)
+}
+
+func rejectGRPC(ctx context.Context, keyType string, retryAfter time.Duration) error {
+	throttledRequests.WithLabelValues("grpc", keyType).Inc()
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfterSeconds(retryAfter))))
+	return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return clientIP(p.Addr.String())
+}
+
+// gatewayCredentials attaches a secret generated at startup to the gateway's
+// calls. It never leaves the process, so clients cannot present it.
+type gatewayCredentials struct {
+	secret string
+}
+
+func newGatewayCredentials() *gatewayCredentials {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("ratelimit: failed to generate gateway secret: " + err.Error())
+	}
+	return &gatewayCredentials{secret: hex.EncodeToString(b)}
+}
+
+func (g *gatewayCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{gatewayHeader: g.secret}, nil
+}
+
+// RequireTransportSecurity is false: the gateway dials the gRPC server over
+// loopback without TLS
+func (g *gatewayCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+func (g *gatewayCredentials) verify(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(gatewayHeader)
+	return len(values) == 1 && subtle.ConstantTimeCompare([]byte(values[0]), []byte(g.secret)) == 1
+}
@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/phoenix/platform/pkg/httperr"
+)
+
+// Middleware limits requests by client IP and rejects those over the limit
+// with 429 Too Many Requests. Callers are limited once authenticated, by the
+// gRPC interceptors behind the gateway. Paths listed in exempt (e.g. health
+// checks and metrics) are never limited.
+func (l *Limiter) Middleware(exempt ...string) func(http.Handler) http.Handler {
+	skip := make(map[string]bool, len(exempt))
+	for _, p := range exempt {
+		skip[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter := l.AllowIP(clientIP(r.RemoteAddr))
+			if !allowed {
+				throttledRequests.WithLabelValues("http", KeyTypeIP).Inc()
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(retryAfter)))
+				httperr.Write(w, r, httperr.RateLimited("rate limit exceeded"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP strips the port from an address. RemoteAddr has already been
+// rewritten by RealIP when the request came through a trusted proxy.
+func clientIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,174 @@
+// Package ratelimit implements per-IP and per-caller token bucket limits for
+// the platform API, shared by the HTTP router and the gRPC server. Every
+// request is limited by client IP where it enters the API; authenticated
+// calls are additionally limited by caller once their credentials have been
+// verified.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+
+	"github.com/phoenix/platform/pkg/semconv"
+)
+
+var throttledRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: semconv.APIRequestsThrottled,
+	Help: "Requests rejected by the API rate limiter",
+}, []string{semconv.LabelTransport, semconv.LabelKeyType})
+
+// Key types used to label limiter buckets
+const (
+	KeyTypeToken = "token"
+	KeyTypeIP    = "ip"
+)
+
+const (
+	// idleTTL is how long an unused bucket is kept before it is evicted
+	idleTTL = 10 * time.Minute
+	// defaultMaxBuckets bounds the memory used by buckets
+	defaultMaxBuckets = 100000
+)
+
+// Config holds the bucket sizes. A zero rate disables that kind of limit.
+type Config struct {
+	// TokenRPS and TokenBurst limit each authenticated caller, user or API key
+	TokenRPS   float64
+	TokenBurst int
+	IPRPS      float64
+	IPBurst    int
+	// MaxBuckets bounds the number of buckets kept; when it is reached the
+	// least recently used bucket is dropped. Default 100000.
+	MaxBuckets int
+}
+
+// Limiter tracks one token bucket per authenticated caller and per client IP
+type Limiter struct {
+	config  Config
+	gateway *gatewayCredentials
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func New(config Config) *Limiter {
+	l := newLimiter(config)
+	go l.evictLoop()
+	return l
+}
+
+func newLimiter(config Config) *Limiter {
+	if config.MaxBuckets <= 0 {
+		config.MaxBuckets = defaultMaxBuckets
+	}
+	return &Limiter{
+		config:  config,
+		gateway: newGatewayCredentials(),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// AllowIP consumes a token from the bucket of a client IP. When the request
+// is rejected it returns the time after which a retry may succeed.
+func (l *Limiter) AllowIP(ip string) (bool, time.Duration) {
+	return l.allow(KeyTypeIP+":"+ip, l.config.IPRPS, l.config.IPBurst)
+}
+
+// AllowCaller consumes a token from the bucket of an authenticated caller.
+// caller must come from verified credentials, never from the raw request.
+func (l *Limiter) AllowCaller(caller string) (bool, time.Duration) {
+	return l.allow(KeyTypeToken+":"+caller, l.config.TokenRPS, l.config.TokenBurst)
+}
+
+func (l *Limiter) allow(key string, limit float64, burst int) (bool, time.Duration) {
+	if limit <= 0 {
+		return true, 0
+	}
+
+	b := l.bucket(key, limit, burst)
+	reservation := b.Reserve()
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		return false, delay
+	}
+	return true, 0
+}
+
+func (l *Limiter) bucket(key string, limit float64, burst int) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.config.MaxBuckets {
+			l.evictOldest()
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit), burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter
+}
+
+// evictOldest drops idle buckets, or the least recently used one when none
+// is idle. Callers hold l.mu.
+func (l *Limiter) evictOldest() {
+	cutoff := l.now().Add(-idleTTL)
+	var oldestKey string
+	var oldest time.Time
+	for key, b := range l.buckets {
+		if b.lastSeen.Before(cutoff) {
+			delete(l.buckets, key)
+			continue
+		}
+		if oldestKey == "" || b.lastSeen.Before(oldest) {
+			oldestKey, oldest = key, b.lastSeen
+		}
+	}
+	if len(l.buckets) >= l.config.MaxBuckets {
+		delete(l.buckets, oldestKey)
+	}
+}
+
+func (l *Limiter) evictLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := l.now().Add(-idleTTL)
+		l.mu.Lock()
+		for key, b := range l.buckets {
+			if b.lastSeen.Before(cutoff) {
+				delete(l.buckets, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// retryAfterSeconds rounds a delay up to whole seconds for Retry-After headers
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
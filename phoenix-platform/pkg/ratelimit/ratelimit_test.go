@@ -0,0 +1,240 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		requests func(l *Limiter) []bool
+		want     []bool
+	}{
+		{
+			name:   "ip burst then throttled",
+			config: Config{IPRPS: 1, IPBurst: 2},
+			requests: func(l *Limiter) []bool {
+				return []bool{allowIP(l, "10.0.0.1"), allowIP(l, "10.0.0.1"), allowIP(l, "10.0.0.1")}
+			},
+			want: []bool{true, true, false},
+		},
+		{
+			name:   "ips have separate buckets",
+			config: Config{IPRPS: 1, IPBurst: 1},
+			requests: func(l *Limiter) []bool {
+				return []bool{allowIP(l, "10.0.0.1"), allowIP(l, "10.0.0.2"), allowIP(l, "10.0.0.1")}
+			},
+			want: []bool{true, true, false},
+		},
+		{
+			name:   "zero rate disables the ip limit",
+			config: Config{IPRPS: 0, IPBurst: 1},
+			requests: func(l *Limiter) []bool {
+				return []bool{allowIP(l, "10.0.0.1"), allowIP(l, "10.0.0.1"), allowIP(l, "10.0.0.1")}
+			},
+			want: []bool{true, true, true},
+		},
+		{
+			name:   "zero burst allows one request",
+			config: Config{IPRPS: 1},
+			requests: func(l *Limiter) []bool {
+				return []bool{allowIP(l, "10.0.0.1"), allowIP(l, "10.0.0.1")}
+			},
+			want: []bool{true, false},
+		},
+		{
+			name:   "callers have separate buckets",
+			config: Config{TokenRPS: 1, TokenBurst: 1},
+			requests: func(l *Limiter) []bool {
+				return []bool{allowCaller(l, "alice"), allowCaller(l, "bob"), allowCaller(l, "alice")}
+			},
+			want: []bool{true, true, false},
+		},
+		{
+			name:   "caller and ip buckets are independent",
+			config: Config{TokenRPS: 1, TokenBurst: 1, IPRPS: 1, IPBurst: 1},
+			requests: func(l *Limiter) []bool {
+				return []bool{allowIP(l, "alice"), allowCaller(l, "alice"), allowIP(l, "alice")}
+			},
+			want: []bool{true, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.requests(newLimiter(tt.config))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("allowed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowRetryAfter(t *testing.T) {
+	l := newLimiter(Config{IPRPS: 0.5, IPBurst: 1})
+	l.AllowIP("10.0.0.1")
+
+	allowed, retryAfter := l.AllowIP("10.0.0.1")
+	if allowed {
+		t.Fatal("second request allowed")
+	}
+	if retryAfter <= time.Second || retryAfter > 2*time.Second {
+		t.Errorf("retryAfter = %v, want between 1s and 2s", retryAfter)
+	}
+	if got := retryAfterSeconds(retryAfter); got != 2 {
+		t.Errorf("retryAfterSeconds = %d, want 2", got)
+	}
+}
+
+func TestBucketsAreBounded(t *testing.T) {
+	l := newLimiter(Config{IPRPS: 1, IPBurst: 1, MaxBuckets: 3})
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Second)
+		l.AllowIP(fmt.Sprintf("10.0.0.%d", i))
+	}
+	if len(l.buckets) != 3 {
+		t.Fatalf("kept %d buckets, want 3", len(l.buckets))
+	}
+	for i := 7; i < 10; i++ {
+		if _, ok := l.buckets[fmt.Sprintf("%s:10.0.0.%d", KeyTypeIP, i)]; !ok {
+			t.Errorf("most recent bucket 10.0.0.%d was evicted", i)
+		}
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    []string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "untrusted peer cannot spoof forwarded headers",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "203.0.113.7:4321",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.1"},
+			want:       "203.0.113.7:4321",
+		},
+		{
+			name:       "no trusted proxies ignores forwarded headers",
+			remoteAddr: "10.0.0.5:4321",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			want:       "10.0.0.5:4321",
+		},
+		{
+			name:       "trusted proxy",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.5:4321",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			want:       "198.51.100.1:4321",
+		},
+		{
+			name:       "addresses prepended by the client are skipped",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.5:4321",
+			headers:    map[string]string{"X-Forwarded-For": "192.0.2.99, 198.51.100.1, 10.0.0.9"},
+			want:       "198.51.100.1:4321",
+		},
+		{
+			name:       "trusted proxy by single ip with x-real-ip",
+			trusted:    []string{"10.0.0.5"},
+			remoteAddr: "10.0.0.5:4321",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.1"},
+			want:       "198.51.100.1:4321",
+		},
+		{
+			name:       "ipv6 client",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.5:4321",
+			headers:    map[string]string{"X-Forwarded-For": "2001:db8::1"},
+			want:       "[2001:db8::1]:4321",
+		},
+		{
+			name:       "malformed forwarded address is ignored",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.5:4321",
+			headers:    map[string]string{"X-Forwarded-For": "not-an-ip"},
+			want:       "10.0.0.5:4321",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware, err := RealIP(tt.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			var got string
+			middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			})).ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIPRejectsInvalidProxies(t *testing.T) {
+	for _, entry := range []string{"10.0.0", "10.0.0.0/33", "proxy.internal"} {
+		if _, err := RealIP([]string{entry}); err == nil {
+			t.Errorf("RealIP(%q) succeeded", entry)
+		}
+	}
+}
+
+func TestGatewayCredentials(t *testing.T) {
+	l := newLimiter(Config{})
+	md, err := l.GatewayCredentials().GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		md   metadata.MD
+		want bool
+	}{
+		{"gateway", metadata.New(md), true},
+		{"no secret", metadata.MD{}, false},
+		{"wrong secret", metadata.Pairs(gatewayHeader, "guess"), false},
+		{"other limiter", metadata.Pairs(gatewayHeader, newGatewayCredentials().secret), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			if got := l.gateway.verify(ctx); got != tt.want {
+				t.Errorf("verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func allowIP(l *Limiter, ip string) bool {
+	allowed, _ := l.AllowIP(ip)
+	return allowed
+}
+
+func allowCaller(l *Limiter, caller string) bool {
+	allowed, _ := l.AllowCaller(caller)
+	return allowed
+}
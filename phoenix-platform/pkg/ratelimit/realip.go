@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP rewrites RemoteAddr to the client address reported in
+// X-Forwarded-For or X-Real-IP, but only for requests sent by one of the
+// trusted proxies, given as IPs or CIDRs. Without trusted proxies the
+// headers are ignored, since any client can set them. RemoteAddr keeps its
+// host:port form, with the proxy's port, so handlers splitting it (such as
+// the gRPC gateway adding X-Forwarded-For) still work.
+func RealIP(trusted []string) (func(http.Handler) http.Handler, error) {
+	nets, err := parseNets(trusted)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := forwardedIP(r, nets); ip != "" {
+				_, port, err := net.SplitHostPort(r.RemoteAddr)
+				if err != nil {
+					port = "0"
+				}
+				r.RemoteAddr = net.JoinHostPort(ip, port)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// forwardedIP returns the client address a trusted proxy reported, or "".
+// X-Forwarded-For is read right to left so addresses prepended by the client
+// are skipped: the first address not belonging to a trusted proxy is the one
+// the outermost trusted proxy saw.
+func forwardedIP(r *http.Request, trusted []*net.IPNet) string {
+	if !contains(trusted, net.ParseIP(clientIP(r.RemoteAddr))) {
+		return ""
+	}
+
+	if header := r.Header.Values("X-Forwarded-For"); len(header) > 0 {
+		hops := strings.Split(strings.Join(header, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				return ""
+			}
+			if !contains(trusted, ip) {
+				return ip.String()
+			}
+		}
+		return ""
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+func parseNets(entries []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
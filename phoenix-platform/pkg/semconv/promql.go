@@ -0,0 +1,46 @@
+package semconv
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Matcher is one label matcher of a PromQL selector
+type Matcher struct {
+	Label string
+	Op    string
+	Value string
+}
+
+// Eq matches a label exactly
+func Eq(label, value string) Matcher {
+	return Matcher{Label: label, Op: "=", Value: value}
+}
+
+// Re matches a label against a regular expression
+func Re(label, pattern string) Matcher {
+	return Matcher{Label: label, Op: "=~", Value: pattern}
+}
+
+// Selector builds metric{label="value",...} with quoted values
+func Selector(metric string, matchers ...Matcher) string {
+	if len(matchers) == 0 {
+		return metric
+	}
+
+	parts := make([]string, len(matchers))
+	for i, m := range matchers {
+		parts[i] = m.Label + m.Op + strconv.Quote(m.Value)
+	}
+	return metric + "{" + strings.Join(parts, ",") + "}"
+}
+
+// SumBy wraps expr in sum by (labels) (...)
+func SumBy(expr string, labels ...string) string {
+	return "sum by (" + strings.Join(labels, ", ") + ") (" + expr + ")"
+}
+
+// CountBy wraps expr in count by (labels) (...)
+func CountBy(expr string, labels ...string) string {
+	return "count by (" + strings.Join(labels, ", ") + ") (" + expr + ")"
+}
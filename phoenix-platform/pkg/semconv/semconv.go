@@ -0,0 +1,129 @@
+// Package semconv is the single definition of the metric names, label keys
+// and recording rule names Phoenix components emit and query. Use these
+// constants instead of string literals so a rename cannot silently break
+// queries in another service.
+package semconv
+
+// Prometheus metric names
+const (
+	ProcessCardinality        = "phoenix_process_cardinality"
+	PipelineBytesExported     = "phoenix_pipeline_bytes_exported"
+	ProcessCPUTime            = "process_cpu_time"
+	ContainerCPUUsage         = "container_cpu_usage_seconds_total"
+	ContainerMemoryWorkingSet = "container_memory_working_set_bytes"
+	APIRequestsThrottled      = "phoenix_api_requests_throttled_total"
+	NotificationsTotal        = "phoenix_notifications_total"
+	PromClientQueriesTotal    = "phoenix_promclient_queries_total"
+	PromClientQueryDuration   = "phoenix_promclient_query_duration_seconds"
+	PromClientRetriesTotal    = "phoenix_promclient_retries_total"
+
+	// Collector self-telemetry
+	CollectorExporterSentPoints       = "otelcol_exporter_sent_metric_points"
+	CollectorExporterSendFailedPoints = "otelcol_exporter_send_failed_metric_points"
+)
+
+// Recording rule names, produced by cmd/genrules
+const (
+	RuleCardinalityReduction    = "phoenix:cardinality_reduction:percent"
+	RuleCriticalProcessCoverage = "phoenix:critical_process_coverage:percent"
+	RuleSignalPreservation      = "phoenix:signal_preservation_score"
+	RuleCardinalityGrowthRate   = "phoenix:cardinality_growth_rate"
+	RuleEstimatedCostHourly     = "phoenix:estimated_cost:hourly"
+	RuleCollectorCPU            = "phoenix:collector_overhead:cpu_cores"
+	RuleCollectorMemory         = "phoenix:collector_overhead:memory_bytes"
+	RuleExportErrorRatio        = "phoenix:export_error_ratio"
+
+	RuleCardinalitySLOViolation     = "phoenix:cardinality_slo_violation"
+	RuleCardinalitySLOErrorRatio5m  = "phoenix:cardinality_slo_error_ratio:5m"
+	RuleCardinalitySLOErrorRatio30m = "phoenix:cardinality_slo_error_ratio:30m"
+	RuleCardinalitySLOErrorRatio1h  = "phoenix:cardinality_slo_error_ratio:1h"
+	RuleCardinalitySLOErrorRatio6h  = "phoenix:cardinality_slo_error_ratio:6h"
+)
+
+// Prometheus label keys
+const (
+	LabelExperimentID          = "experiment_id"
+	LabelVariant               = "variant"
+	LabelPod                   = "pod"
+	LabelProcessPriority       = "process_priority"
+	LabelProcessExecutableName = "process_executable_name"
+	LabelEnvironment           = "environment"
+	LabelMetricType            = "metric_type"
+	LabelTransport             = "transport"
+	LabelKeyType               = "key_type"
+	LabelChannel               = "channel"
+	LabelResult                = "result"
+	LabelClient                = "client"
+	LabelQuery                 = "query"
+)
+
+// OpenTelemetry metric and attribute names, as emitted by the hostmetrics
+// process scraper and the simulator before Prometheus translation
+const (
+	OTelProcessCPUTime        = "process.cpu.time"
+	OTelProcessMemoryPhysical = "process.memory.physical"
+	OTelProcessMemoryVirtual  = "process.memory.virtual"
+	OTelSimulatorProcesses    = "phoenix.simulator.processes"
+
+	AttrProcessPID            = "process.pid"
+	AttrProcessExecutableName = "process.executable.name"
+	AttrProcessCommandLine    = "process.command_line"
+	AttrServiceName           = "service.name"
+	AttrHostName              = "host.name"
+	AttrSimulatorProfile      = "simulator.profile"
+	AttrState                 = "state"
+)
+
+// Priority values of LabelProcessPriority
+const (
+	PriorityCritical = "critical"
+)
+
+// Metric types used in LabelMetricType on recording rules
+const (
+	MetricTypeEfficiency  = "efficiency"
+	MetricTypeCost        = "cost"
+	MetricTypePerformance = "performance"
+	MetricTypeQuality     = "quality"
+)
+
+// Metric describes a registered series
+type Metric struct {
+	Name   string
+	Help   string
+	Labels []string
+}
+
+// Registry lists every Phoenix-owned series and recording rule
+var Registry = []Metric{
+	{ProcessCardinality, "Distinct processes reported per pipeline variant", []string{LabelExperimentID, LabelVariant}},
+	{PipelineBytesExported, "Bytes exported by each pipeline variant", []string{LabelExperimentID, LabelVariant}},
+	{APIRequestsThrottled, "Requests rejected by the API rate limiter", []string{LabelTransport, LabelKeyType}},
+	{NotificationsTotal, "Notifications handled per channel", []string{LabelChannel, LabelResult}},
+	{PromClientQueriesTotal, "Prometheus queries by calling client, query name and result", []string{LabelClient, LabelQuery, LabelResult}},
+	{PromClientQueryDuration, "Time spent on Prometheus queries that were sent, retries included", []string{LabelClient, LabelQuery}},
+	{PromClientRetriesTotal, "Prometheus query attempts that were retried", []string{LabelClient, LabelQuery}},
+	{RuleCardinalityReduction, "Cardinality reduction of a candidate against the baseline, in percent", []string{LabelExperimentID, LabelVariant}},
+	{RuleCriticalProcessCoverage, "Share of critical baseline processes kept by a candidate, in percent", []string{LabelExperimentID, LabelVariant}},
+	{RuleSignalPreservation, "Weighted share of baseline processes kept by a candidate, 0..1", []string{LabelExperimentID, LabelVariant}},
+	{RuleCardinalityGrowthRate, "Change of process cardinality per hour", []string{LabelExperimentID, LabelVariant}},
+	{RuleEstimatedCostHourly, "Estimated ingest cost per hour", []string{LabelExperimentID, LabelVariant}},
+	{RuleCollectorCPU, "CPU cores used by collector pods", []string{LabelPod}},
+	{RuleCollectorMemory, "Working set of collector pods", []string{LabelPod}},
+	{RuleExportErrorRatio, "Share of metric points each variant failed to export, 0..1", []string{LabelExperimentID, LabelVariant}},
+	{RuleCardinalitySLOViolation, "1 while a candidate is over the cardinality SLO threshold, else 0", []string{LabelExperimentID, LabelVariant}},
+	{RuleCardinalitySLOErrorRatio5m, "Share of the last 5m a candidate was over the cardinality SLO threshold", []string{LabelExperimentID, LabelVariant}},
+	{RuleCardinalitySLOErrorRatio30m, "Share of the last 30m a candidate was over the cardinality SLO threshold", []string{LabelExperimentID, LabelVariant}},
+	{RuleCardinalitySLOErrorRatio1h, "Share of the last 1h a candidate was over the cardinality SLO threshold", []string{LabelExperimentID, LabelVariant}},
+	{RuleCardinalitySLOErrorRatio6h, "Share of the last 6h a candidate was over the cardinality SLO threshold", []string{LabelExperimentID, LabelVariant}},
+}
+
+// Lookup returns the registered metric with the given name
+func Lookup(name string) (Metric, bool) {
+	for _, m := range Registry {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Metric{}, false
+}
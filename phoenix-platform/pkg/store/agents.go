@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Agent is the last reported state of a collector
+type Agent struct {
+	ID                  string
+	Hostname            string
+	Version             string
+	PolicyHash          string
+	Mode                string
+	CardinalityEstimate int64
+	Labels              map[string]string
+	FirstSeen           time.Time
+	LastSeen            time.Time
+}
+
+// AgentFilter narrows ListAgents; zero values match everything
+type AgentFilter struct {
+	Version    string
+	PolicyHash string
+	Mode       string
+	// SeenBefore/SeenAfter select agents by last heartbeat
+	SeenBefore time.Time
+	SeenAfter  time.Time
+	Limit      int
+	Offset     int
+}
+
+// AgentStore keeps the collector inventory
+type AgentStore interface {
+	UpsertAgent(ctx context.Context, agent *Agent) error
+	ListAgents(ctx context.Context, filter AgentFilter) ([]*Agent, int, error)
+}
+
+// PostgresAgentStore keeps agents in the agents table
+type PostgresAgentStore struct {
+	db *sql.DB
+}
+
+func NewPostgresAgentStore(db *sql.DB) *PostgresAgentStore {
+	return &PostgresAgentStore{db: db}
+}
+
+// UpsertAgent records a heartbeat, creating the agent on first report
+func (s *PostgresAgentStore) UpsertAgent(ctx context.Context, agent *Agent) error {
+	labels, err := json.Marshal(agent.Labels)
+	if err != nil {
+		return err
+	}
+	if agent.Labels == nil {
+		labels = []byte("{}")
+	}
+
+	return s.db.QueryRowContext(ctx, `
+		INSERT INTO agents (id, hostname, version, policy_hash, mode, cardinality_estimate, labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE
+		SET hostname = EXCLUDED.hostname,
+		    version = EXCLUDED.version,
+		    policy_hash = EXCLUDED.policy_hash,
+		    mode = EXCLUDED.mode,
+		    cardinality_estimate = EXCLUDED.cardinality_estimate,
+		    labels = EXCLUDED.labels,
+		    last_seen = NOW()
+		RETURNING first_seen, last_seen`,
+		agent.ID, agent.Hostname, agent.Version, agent.PolicyHash, agent.Mode, agent.CardinalityEstimate, labels,
+	).Scan(&agent.FirstSeen, &agent.LastSeen)
+}
+
+func (s *PostgresAgentStore) ListAgents(ctx context.Context, filter AgentFilter) ([]*Agent, int, error) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Version != "" {
+		add("version = $%d", filter.Version)
+	}
+	if filter.PolicyHash != "" {
+		add("policy_hash = $%d", filter.PolicyHash)
+	}
+	if filter.Mode != "" {
+		add("mode = $%d", filter.Mode)
+	}
+	if !filter.SeenBefore.IsZero() {
+		add("last_seen < $%d", filter.SeenBefore)
+	}
+	if !filter.SeenAfter.IsZero() {
+		add("last_seen >= $%d", filter.SeenAfter)
+	}
+
+	clause := ""
+	if len(where) > 0 {
+		clause = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM agents "+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit, filter.Offset)
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, hostname, version, policy_hash, mode, cardinality_estimate, labels, first_seen, last_seen
+		FROM agents
+		%s
+		ORDER BY hostname, id
+		LIMIT $%d OFFSET $%d`, clause, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var agents []*Agent
+	for rows.Next() {
+		a := &Agent{}
+		var labels []byte
+		if err := rows.Scan(&a.ID, &a.Hostname, &a.Version, &a.PolicyHash, &a.Mode, &a.CardinalityEstimate, &labels, &a.FirstSeen, &a.LastSeen); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(labels, &a.Labels); err != nil {
+			return nil, 0, err
+		}
+		agents = append(agents, a)
+	}
+	return agents, total, rows.Err()
+}
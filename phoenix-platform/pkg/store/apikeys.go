@@ -0,0 +1,144 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrAPIKeyNotFound is returned for unknown, revoked or expired API keys
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKey is a machine credential. Only the hash of the secret is stored.
+type APIKey struct {
+	ID     string
+	Tenant string
+	Name   string
+	// Prefix is the public part of the key used to look it up
+	Prefix     string
+	Hash       string
+	Scopes     []string
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+}
+
+// APIKeyStore keeps API keys
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	// UseAPIKey returns the active key with the given prefix and records
+	// that it was used
+	UseAPIKey(ctx context.Context, prefix string) (*APIKey, error)
+	GetAPIKey(ctx context.Context, id string) (*APIKey, error)
+	// ListAPIKeys returns the keys of one tenant, or of every tenant when
+	// tenant is empty, newest first
+	ListAPIKeys(ctx context.Context, tenant string) ([]*APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+// PostgresAPIKeyStore keeps keys in the api_keys table
+type PostgresAPIKeyStore struct {
+	db *sql.DB
+}
+
+func NewPostgresAPIKeyStore(db *sql.DB) *PostgresAPIKeyStore {
+	return &PostgresAPIKeyStore{db: db}
+}
+
+const apiKeyColumns = `id, tenant, name, prefix, key_hash, scopes, created_by, created_at, expires_at, last_used_at, revoked_at`
+
+func (s *PostgresAPIKeyStore) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return err
+	}
+	if key.Scopes == nil {
+		scopes = []byte("[]")
+	}
+
+	return s.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (id, tenant, name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at`,
+		key.ID, key.Tenant, key.Name, key.Prefix, key.Hash, scopes, key.CreatedBy,
+		sql.NullTime{Time: key.ExpiresAt, Valid: !key.ExpiresAt.IsZero()},
+	).Scan(&key.CreatedAt)
+}
+
+func (s *PostgresAPIKeyStore) UseAPIKey(ctx context.Context, prefix string) (*APIKey, error) {
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE prefix = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING `+apiKeyColumns, prefix)
+	return scanAPIKey(row)
+}
+
+func (s *PostgresAPIKeyStore) GetAPIKey(ctx context.Context, id string) (*APIKey, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id)
+	return scanAPIKey(row)
+}
+
+func (s *PostgresAPIKeyStore) ListAPIKeys(ctx context.Context, tenant string) ([]*APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE $1 = '' OR tenant = $1
+		ORDER BY created_at DESC`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (s *PostgresAPIKeyStore) RevokeAPIKey(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*APIKey, error) {
+	key := &APIKey{}
+	var scopes []byte
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&key.ID, &key.Tenant, &key.Name, &key.Prefix, &key.Hash, &scopes,
+		&key.CreatedBy, &key.CreatedAt, &expiresAt, &lastUsedAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return nil, err
+	}
+	key.ExpiresAt = expiresAt.Time
+	key.LastUsedAt = lastUsedAt.Time
+	key.RevokedAt = revokedAt.Time
+	return key, nil
+}
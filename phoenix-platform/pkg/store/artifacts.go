@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+)
+
+// Artifact kinds persisted for each experiment variant
+const (
+	ArtifactKindCollectorConfig = "collector-config"
+	ArtifactKindManifest        = "manifest"
+	ArtifactKindParameters      = "parameters"
+)
+
+// Artifact is a rendered file kept so an experiment can be reproduced later
+type Artifact struct {
+	ExperimentID string
+	Variant      string
+	Kind         string
+	Name         string
+	ContentType  string
+	Content      []byte
+	SHA256       string
+	CreatedAt    time.Time
+}
+
+// ArtifactStore persists rendered experiment artifacts
+type ArtifactStore interface {
+	SaveArtifacts(ctx context.Context, artifacts []*Artifact) error
+	ListArtifacts(ctx context.Context, experimentID string) ([]*Artifact, error)
+}
+
+// PostgresArtifactStore keeps artifacts in the experiment_artifacts table
+type PostgresArtifactStore struct {
+	db *sql.DB
+}
+
+func NewPostgresArtifactStore(db *sql.DB) *PostgresArtifactStore {
+	return &PostgresArtifactStore{db: db}
+}
+
+// SaveArtifacts stores the artifacts in a single transaction. Re-rendering a
+// variant replaces the previous artifact of the same kind.
+func (s *PostgresArtifactStore) SaveArtifacts(ctx context.Context, artifacts []*Artifact) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, a := range artifacts {
+		sum := sha256.Sum256(a.Content)
+		a.SHA256 = hex.EncodeToString(sum[:])
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO experiment_artifacts (experiment_id, variant, kind, name, content_type, content, sha256)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (experiment_id, variant, kind) DO UPDATE
+			SET name = EXCLUDED.name,
+			    content_type = EXCLUDED.content_type,
+			    content = EXCLUDED.content,
+			    sha256 = EXCLUDED.sha256,
+			    created_at = NOW()
+			RETURNING created_at`,
+			a.ExperimentID, a.Variant, a.Kind, a.Name, a.ContentType, a.Content, a.SHA256,
+		).Scan(&a.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *PostgresArtifactStore) ListArtifacts(ctx context.Context, experimentID string) ([]*Artifact, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT experiment_id, variant, kind, name, content_type, content, sha256, created_at
+		FROM experiment_artifacts
+		WHERE experiment_id = $1
+		ORDER BY variant, kind`, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []*Artifact
+	for rows.Next() {
+		a := &Artifact{}
+		if err := rows.Scan(&a.ExperimentID, &a.Variant, &a.Kind, &a.Name, &a.ContentType, &a.Content, &a.SHA256, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
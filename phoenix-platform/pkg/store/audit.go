@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditEntry records one mutating API call
+type AuditEntry struct {
+	ID   int64
+	Time time.Time
+	// Actor is the user name, or the API key ID for machine credentials
+	Actor      string
+	ActorType  string
+	Tenant     string
+	Method     string
+	Resource   string
+	RemoteAddr string
+	// Code is the gRPC status code name of the result, e.g. OK
+	Code  string
+	Error string
+}
+
+// AuditFilter narrows ListAuditEntries; zero values match everything
+type AuditFilter struct {
+	Actor  string
+	Tenant string
+	Method string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
+
+// AuditStore keeps the audit log
+type AuditStore interface {
+	RecordAuditEntry(ctx context.Context, entry *AuditEntry) error
+	// ListAuditEntries returns one page of entries, newest first, and the
+	// total number of matching entries
+	ListAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, int, error)
+}
+
+// PostgresAuditStore keeps entries in the audit_log table
+type PostgresAuditStore struct {
+	db *sql.DB
+}
+
+func NewPostgresAuditStore(db *sql.DB) *PostgresAuditStore {
+	return &PostgresAuditStore{db: db}
+}
+
+func (s *PostgresAuditStore) RecordAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	return s.db.QueryRowContext(ctx, `
+		INSERT INTO audit_log (actor, actor_type, tenant, method, resource, remote_addr, code, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, time`,
+		entry.Actor, entry.ActorType, entry.Tenant, entry.Method, entry.Resource, entry.RemoteAddr, entry.Code, entry.Error,
+	).Scan(&entry.ID, &entry.Time)
+}
+
+func (s *PostgresAuditStore) ListAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, int, error) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.Tenant != "" {
+		add("tenant = $%d", filter.Tenant)
+	}
+	if filter.Method != "" {
+		add("method = $%d", filter.Method)
+	}
+	if !filter.Since.IsZero() {
+		add("time >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("time < $%d", filter.Until)
+	}
+
+	clause := ""
+	if len(where) > 0 {
+		clause = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log "+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit, filter.Offset)
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, time, actor, actor_type, tenant, method, resource, remote_addr, code, error
+		FROM audit_log
+		%s
+		ORDER BY time DESC, id DESC
+		LIMIT $%d OFFSET $%d`, clause, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		e := &AuditEntry{}
+		if err := rows.Scan(&e.ID, &e.Time, &e.Actor, &e.ActorType, &e.Tenant, &e.Method, &e.Resource, &e.RemoteAddr, &e.Code, &e.Error); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
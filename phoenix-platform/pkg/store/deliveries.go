@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// PostgresDeliveryStore records which consumer handled which event, so that
+// events fanned out to every API replica are acted on once. Claims older
+// than the retention are pruned while claiming.
+type PostgresDeliveryStore struct {
+	db        *sql.DB
+	retention time.Duration
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func NewPostgresDeliveryStore(db *sql.DB, retention time.Duration) *PostgresDeliveryStore {
+	return &PostgresDeliveryStore{db: db, retention: retention}
+}
+
+// ClaimDelivery reports whether the caller is the first to claim the event
+// for the consumer
+func (s *PostgresDeliveryStore) ClaimDelivery(ctx context.Context, consumer, eventID string) (bool, error) {
+	s.prune(ctx)
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO event_deliveries (consumer, event_id) VALUES ($1, $2)
+		ON CONFLICT (consumer, event_id) DO NOTHING`,
+		consumer, eventID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// prune deletes expired claims at most once per retention period. Failures
+// are retried on the next period.
+func (s *PostgresDeliveryStore) prune(ctx context.Context) {
+	s.mu.Lock()
+	due := time.Since(s.lastPrune) >= s.retention
+	if due {
+		s.lastPrune = time.Now()
+	}
+	s.mu.Unlock()
+
+	if due {
+		s.db.ExecContext(ctx, "DELETE FROM event_deliveries WHERE delivered_at < $1", time.Now().Add(-s.retention))
+	}
+}
@@ -0,0 +1,284 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the Postgres advisory lock key held while migrations run,
+// so that replicas starting at the same time don't race on the schema.
+const migrationLockID = 0x70686f656e6978 // "phoenix"
+
+// ErrNoMigrationsTable is returned by MigrationStatus when the database has
+// never been migrated
+var ErrNoMigrationsTable = errors.New("no migrations table")
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Checksum identifies the content of the migration, so that a file edited
+// after it was applied is detected
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.SQL))
+	return hex.EncodeToString(sum[:])
+}
+
+// AppliedMigration is a row of the schema_version table. Checksum is empty
+// for rows written before checksums were recorded.
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// LoadMigrations returns the embedded migrations ordered by version.
+// Files are named <version>_<name>.sql, e.g. 0001_create_experiments.sql.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrate applies all pending migrations and returns the ones it applied.
+// Each migration runs in its own transaction, and the whole run is guarded
+// by a session-level advisory lock. Nothing is applied if a migration that
+// already ran has since been edited.
+func Migrate(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if err := ensureSchemaVersionTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(ctx, conn, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksums(migrations, applied); err != nil {
+		return nil, err
+	}
+	if err := backfillChecksums(ctx, conn, migrations, applied); err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return ran, fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+
+	return ran, nil
+}
+
+// MigrationStatus returns the applied migrations and the embedded migrations
+// that have not been applied yet. It only reads the database and returns
+// ErrNoMigrationsTable if schema_version does not exist.
+func MigrationStatus(ctx context.Context, db *sql.DB) ([]AppliedMigration, []Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	var exists, hasChecksum bool
+	err = conn.QueryRowContext(ctx, `
+		SELECT to_regclass('schema_version') IS NOT NULL,
+		       EXISTS (SELECT 1 FROM information_schema.columns
+		               WHERE table_name = 'schema_version' AND column_name = 'checksum'
+		                 AND table_schema = current_schema())`).Scan(&exists, &hasChecksum)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to inspect schema_version: %w", err)
+	}
+	if !exists {
+		return nil, nil, ErrNoMigrationsTable
+	}
+
+	applied, err := appliedVersions(ctx, conn, hasChecksum)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	appliedList := make([]AppliedMigration, 0, len(applied))
+	for _, a := range applied {
+		appliedList = append(appliedList, a)
+	}
+	sort.Slice(appliedList, func(i, j int) bool {
+		return appliedList[i].Version < appliedList[j].Version
+	})
+
+	var pending []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+
+	return appliedList, pending, nil
+}
+
+func ensureSchemaVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version    INTEGER PRIMARY KEY,
+			name       VARCHAR(255) NOT NULL,
+			checksum   VARCHAR(64),
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "ALTER TABLE schema_version ADD COLUMN IF NOT EXISTS checksum VARCHAR(64)"); err != nil {
+		return fmt.Errorf("failed to add schema_version checksum: %w", err)
+	}
+	return nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn, withChecksum bool) (map[int]AppliedMigration, error) {
+	query := "SELECT version, name, '', applied_at FROM schema_version"
+	if withChecksum {
+		query = "SELECT version, name, COALESCE(checksum, ''), applied_at FROM schema_version"
+	}
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_version: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]AppliedMigration)
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[a.Version] = a
+	}
+	return applied, rows.Err()
+}
+
+// verifyChecksums fails if an applied migration no longer matches its file
+func verifyChecksums(migrations []Migration, applied map[int]AppliedMigration) error {
+	var modified []string
+	for _, m := range migrations {
+		a, ok := applied[m.Version]
+		if ok && a.Checksum != "" && a.Checksum != m.Checksum() {
+			modified = append(modified, fmt.Sprintf("%04d_%s", m.Version, m.Name))
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("applied migrations were modified, add a new migration instead: %s", strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// backfillChecksums records the checksum of migrations applied before
+// checksums were tracked
+func backfillChecksums(ctx context.Context, conn *sql.Conn, migrations []Migration, applied map[int]AppliedMigration) error {
+	for _, m := range migrations {
+		if a, ok := applied[m.Version]; ok && a.Checksum == "" {
+			if _, err := conn.ExecContext(ctx,
+				"UPDATE schema_version SET checksum = $1 WHERE version = $2", m.Checksum(), m.Version); err != nil {
+				return fmt.Errorf("failed to record checksum of migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_version (version, name, checksum) VALUES ($1, $2, $3)", m.Version, m.Name, m.Checksum()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
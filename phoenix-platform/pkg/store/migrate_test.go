@@ -0,0 +1,304 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMigrate(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeSchema{}
+
+	ran, err := Migrate(context.Background(), fake.open())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != len(migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(ran), len(migrations))
+	}
+	for _, m := range migrations {
+		if got := fake.applied[m.Version].Checksum; got != m.Checksum() {
+			t.Errorf("migration %d recorded checksum %q, want %q", m.Version, got, m.Checksum())
+		}
+	}
+	assertLocked(t, fake.statements())
+
+	fake.reset()
+	ran, err = Migrate(context.Background(), fake.open())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 0 {
+		t.Errorf("second run applied %d migrations", len(ran))
+	}
+	assertLocked(t, fake.statements())
+}
+
+func TestMigrateChecksumMismatch(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeSchema{exists: true, hasChecksum: true}
+	edited := migrations[0]
+	fake.apply(edited.Version, edited.Name, "0000")
+
+	ran, err := Migrate(context.Background(), fake.open())
+	want := fmt.Sprintf("%04d_%s", edited.Version, edited.Name)
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("err = %v, want a checksum error naming %s", err, want)
+	}
+	if len(ran) != 0 || len(fake.applied) != 1 {
+		t.Errorf("pending migrations were applied after a checksum mismatch")
+	}
+	assertLocked(t, fake.statements())
+}
+
+func TestMigrateBackfillsChecksums(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeSchema{exists: true}
+	fake.apply(migrations[0].Version, migrations[0].Name, "")
+
+	if _, err := Migrate(context.Background(), fake.open()); err != nil {
+		t.Fatal(err)
+	}
+	if !fake.hasChecksum {
+		t.Error("checksum column was not added")
+	}
+	if got := fake.applied[migrations[0].Version].Checksum; got != migrations[0].Checksum() {
+		t.Errorf("checksum was not backfilled: got %q", got)
+	}
+}
+
+func TestMigrateReleasesLockOnFailure(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeSchema{failOn: migrations[1].SQL}
+
+	ran, err := Migrate(context.Background(), fake.open())
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("%04d_%s", migrations[1].Version, migrations[1].Name)) {
+		t.Fatalf("err = %v, want the failing migration", err)
+	}
+	if len(ran) != 1 {
+		t.Errorf("reported %d applied migrations, want 1", len(ran))
+	}
+	assertLocked(t, fake.statements())
+}
+
+func TestMigrationStatus(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no migrations table", func(t *testing.T) {
+		fake := &fakeSchema{}
+		if _, _, err := MigrationStatus(context.Background(), fake.open()); !errors.Is(err, ErrNoMigrationsTable) {
+			t.Fatalf("err = %v, want %v", err, ErrNoMigrationsTable)
+		}
+		if fake.exists {
+			t.Error("status created the schema_version table")
+		}
+		assertReadOnly(t, fake.statements())
+	})
+
+	t.Run("partially migrated", func(t *testing.T) {
+		fake := &fakeSchema{exists: true, hasChecksum: true}
+		fake.apply(migrations[0].Version, migrations[0].Name, migrations[0].Checksum())
+
+		applied, pending, err := MigrationStatus(context.Background(), fake.open())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applied) != 1 || applied[0].Version != migrations[0].Version || applied[0].Checksum != migrations[0].Checksum() {
+			t.Errorf("applied = %+v", applied)
+		}
+		if len(pending) != len(migrations)-1 {
+			t.Errorf("%d pending migrations, want %d", len(pending), len(migrations)-1)
+		}
+		assertReadOnly(t, fake.statements())
+	})
+
+	t.Run("table without checksums", func(t *testing.T) {
+		fake := &fakeSchema{exists: true}
+		fake.apply(migrations[0].Version, migrations[0].Name, "")
+
+		applied, _, err := MigrationStatus(context.Background(), fake.open())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applied) != 1 || applied[0].Checksum != "" {
+			t.Errorf("applied = %+v", applied)
+		}
+		if fake.hasChecksum {
+			t.Error("status added the checksum column")
+		}
+		assertReadOnly(t, fake.statements())
+	})
+}
+
+// assertLocked checks that every statement ran while the advisory lock was
+// held and that the lock was released
+func assertLocked(t *testing.T, statements []string) {
+	t.Helper()
+	if len(statements) < 2 || !strings.Contains(statements[0], "pg_advisory_lock") || !strings.Contains(statements[len(statements)-1], "pg_advisory_unlock") {
+		t.Errorf("statements did not run under the migration lock: %q", statements)
+	}
+}
+
+func assertReadOnly(t *testing.T, statements []string) {
+	t.Helper()
+	for _, s := range statements {
+		if !strings.HasPrefix(strings.TrimSpace(s), "SELECT") {
+			t.Errorf("status ran %q", s)
+		}
+	}
+}
+
+// fakeSchema is a database/sql driver that understands the statements of
+// the migration runner and records them in order
+type fakeSchema struct {
+	mu          sync.Mutex
+	exists      bool
+	hasChecksum bool
+	applied     map[int]AppliedMigration
+	failOn      string
+	log         []string
+}
+
+func (f *fakeSchema) open() *sql.DB {
+	return sql.OpenDB(fakeConnector{f})
+}
+
+func (f *fakeSchema) apply(version int, name, checksum string) {
+	if f.applied == nil {
+		f.applied = make(map[int]AppliedMigration)
+	}
+	f.applied[version] = AppliedMigration{Version: version, Name: name, Checksum: checksum, AppliedAt: time.Unix(0, 0)}
+}
+
+func (f *fakeSchema) statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.log...)
+}
+
+func (f *fakeSchema) reset() {
+	f.mu.Lock()
+	f.log = nil
+	f.mu.Unlock()
+}
+
+func (f *fakeSchema) exec(query string, args []driver.NamedValue) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, query)
+
+	switch {
+	case f.failOn != "" && query == f.failOn:
+		return errors.New("syntax error")
+	case strings.Contains(query, "pg_advisory_lock"), strings.Contains(query, "pg_advisory_unlock"):
+	case strings.Contains(query, "CREATE TABLE IF NOT EXISTS schema_version"):
+		if !f.exists {
+			f.exists, f.hasChecksum = true, true
+		}
+	case strings.HasPrefix(query, "ALTER TABLE schema_version"):
+		f.hasChecksum = true
+	case strings.HasPrefix(query, "UPDATE schema_version SET checksum"):
+		version := int(args[1].Value.(int64))
+		a := f.applied[version]
+		a.Checksum = args[0].Value.(string)
+		f.applied[version] = a
+	case strings.HasPrefix(query, "INSERT INTO schema_version"):
+		f.apply(int(args[0].Value.(int64)), args[1].Value.(string), args[2].Value.(string))
+	}
+	return nil
+}
+
+func (f *fakeSchema) query(query string) (driver.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, query)
+
+	switch {
+	case strings.Contains(query, "to_regclass"):
+		return &fakeRows{columns: 2, values: [][]driver.Value{{f.exists, f.hasChecksum}}}, nil
+	case strings.Contains(query, "FROM schema_version"):
+		if !f.exists {
+			return nil, errors.New(`relation "schema_version" does not exist`)
+		}
+		if strings.Contains(query, "checksum") && !f.hasChecksum {
+			return nil, errors.New(`column "checksum" does not exist`)
+		}
+		rows := &fakeRows{columns: 4}
+		for _, a := range f.applied {
+			rows.values = append(rows.values, []driver.Value{int64(a.Version), a.Name, a.Checksum, a.AppliedAt})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+type fakeConnector struct{ schema *fakeSchema }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{c.schema}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use the connector") }
+
+type fakeConn struct{ schema *fakeSchema }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.schema.exec(query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.schema.query(query)
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns int
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, r.columns) }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
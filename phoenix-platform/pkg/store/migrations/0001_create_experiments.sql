@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS experiments (
+    id          VARCHAR(64) PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT,
+    owner       VARCHAR(255) NOT NULL,
+    phase       VARCHAR(50) NOT NULL DEFAULT 'PHASE_PENDING',
+    spec        JSONB NOT NULL,
+    status      JSONB,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiments_owner ON experiments (owner);
+CREATE INDEX IF NOT EXISTS idx_experiments_phase ON experiments (phase);
+CREATE INDEX IF NOT EXISTS idx_experiments_created ON experiments (created_at DESC);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS experiment_events (
+    id            BIGSERIAL PRIMARY KEY,
+    experiment_id VARCHAR(64) NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    event_type    VARCHAR(50) NOT NULL,
+    event_data    JSONB,
+    created_by    VARCHAR(255),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_events_experiment ON experiment_events (experiment_id, created_at DESC);
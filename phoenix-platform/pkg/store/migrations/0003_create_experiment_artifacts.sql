@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS experiment_artifacts (
+    id            BIGSERIAL PRIMARY KEY,
+    experiment_id VARCHAR(64) NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    variant       VARCHAR(50) NOT NULL,
+    kind          VARCHAR(50) NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    content_type  VARCHAR(100) NOT NULL,
+    content       BYTEA NOT NULL,
+    sha256        CHAR(64) NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (experiment_id, variant, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_artifacts_experiment ON experiment_artifacts (experiment_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS agents (
+    id                   VARCHAR(255) PRIMARY KEY,
+    hostname             VARCHAR(255) NOT NULL,
+    version              VARCHAR(64) NOT NULL DEFAULT '',
+    policy_hash          VARCHAR(128) NOT NULL DEFAULT '',
+    mode                 VARCHAR(50) NOT NULL DEFAULT '',
+    cardinality_estimate BIGINT NOT NULL DEFAULT 0,
+    labels               JSONB NOT NULL DEFAULT '{}',
+    first_seen           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_agents_last_seen ON agents (last_seen);
+CREATE INDEX IF NOT EXISTS idx_agents_policy_hash ON agents (policy_hash);
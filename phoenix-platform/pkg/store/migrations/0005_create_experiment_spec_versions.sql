@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS experiment_spec_versions (
+    experiment_id VARCHAR(64) NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    version       INTEGER NOT NULL,
+    spec          JSONB NOT NULL,
+    created_by    VARCHAR(255) NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (experiment_id, version)
+);
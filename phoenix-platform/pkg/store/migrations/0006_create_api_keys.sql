@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           VARCHAR(64) PRIMARY KEY,
+    tenant       VARCHAR(255) NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    prefix       VARCHAR(32) NOT NULL UNIQUE,
+    key_hash     VARCHAR(128) NOT NULL,
+    scopes       JSONB NOT NULL DEFAULT '[]',
+    created_by   VARCHAR(255) NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys (tenant);
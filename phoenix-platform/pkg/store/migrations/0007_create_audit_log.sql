@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          BIGSERIAL PRIMARY KEY,
+    time        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor       VARCHAR(255) NOT NULL,
+    actor_type  VARCHAR(32) NOT NULL,
+    tenant      VARCHAR(255) NOT NULL DEFAULT '',
+    method      VARCHAR(255) NOT NULL,
+    resource    VARCHAR(255) NOT NULL DEFAULT '',
+    remote_addr VARCHAR(255) NOT NULL DEFAULT '',
+    code        VARCHAR(32) NOT NULL,
+    error       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_time ON audit_log (time);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor, time);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS experiment_templates (
+    namespace     VARCHAR(63) NOT NULL,
+    name          VARCHAR(63) NOT NULL,
+    description   TEXT NOT NULL DEFAULT '',
+    tags          JSONB NOT NULL DEFAULT '[]',
+    spec          JSONB NOT NULL,
+    usage_count   INTEGER NOT NULL DEFAULT 0,
+    imported_from VARCHAR(64) NOT NULL DEFAULT '',
+    created_by    VARCHAR(255) NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (namespace, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_templates_tags ON experiment_templates USING GIN (tags);
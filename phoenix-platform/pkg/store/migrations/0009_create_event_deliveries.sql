@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS event_deliveries (
+    consumer     VARCHAR(100) NOT NULL,
+    event_id     VARCHAR(64) NOT NULL,
+    delivered_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (consumer, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_delivered ON event_deliveries (delivered_at);
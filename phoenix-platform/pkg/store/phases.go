@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// PhaseStore moves experiments between phases atomically, for transitions
+// that concurrent callers may race on
+type PhaseStore interface {
+	// TransitionPhase moves an experiment from one phase to another and
+	// reports false if it was no longer in the expected phase
+	TransitionPhase(ctx context.Context, experimentID, from, to string) (bool, error)
+}
+
+// PostgresPhaseStore updates the phase column of the experiments table
+type PostgresPhaseStore struct {
+	db *sql.DB
+}
+
+func NewPostgresPhaseStore(db *sql.DB) *PostgresPhaseStore {
+	return &PostgresPhaseStore{db: db}
+}
+
+func (s *PostgresPhaseStore) TransitionPhase(ctx context.Context, experimentID, from, to string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE experiments SET phase = $3, updated_at = NOW()
+		WHERE id = $1 AND phase = $2`,
+		experimentID, from, to)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SpecVersion is one revision of an experiment spec, stored as JSON
+type SpecVersion struct {
+	ExperimentID string
+	Version      int
+	Spec         []byte
+	CreatedBy    string
+	CreatedAt    time.Time
+}
+
+// SpecVersionStore keeps the history of experiment specs
+type SpecVersionStore interface {
+	// SaveSpecVersion appends a revision and sets its version number
+	SaveSpecVersion(ctx context.Context, v *SpecVersion) error
+	ListSpecVersions(ctx context.Context, experimentID string) ([]*SpecVersion, error)
+}
+
+// PostgresSpecVersionStore keeps revisions in the experiment_spec_versions table
+type PostgresSpecVersionStore struct {
+	db *sql.DB
+}
+
+func NewPostgresSpecVersionStore(db *sql.DB) *PostgresSpecVersionStore {
+	return &PostgresSpecVersionStore{db: db}
+}
+
+// SaveSpecVersion numbers the revision after the latest one. The experiment
+// row is locked for the transaction so concurrent saves for the same
+// experiment cannot pick the same number.
+func (s *PostgresSpecVersionStore) SaveSpecVersion(ctx context.Context, v *SpecVersion) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM experiments WHERE id = $1 FOR UPDATE`, v.ExperimentID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO experiment_spec_versions (experiment_id, version, spec, created_by)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3
+		FROM experiment_spec_versions
+		WHERE experiment_id = $1
+		RETURNING version, created_at`,
+		v.ExperimentID, v.Spec, v.CreatedBy,
+	).Scan(&v.Version, &v.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresSpecVersionStore) ListSpecVersions(ctx context.Context, experimentID string) ([]*SpecVersion, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT experiment_id, version, spec, created_by, created_at
+		FROM experiment_spec_versions
+		WHERE experiment_id = $1
+		ORDER BY version`, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*SpecVersion
+	for rows.Next() {
+		v := &SpecVersion{}
+		if err := rows.Scan(&v.ExperimentID, &v.Version, &v.Spec, &v.CreatedBy, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTemplateNotFound is returned for unknown catalog templates
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrTemplateExists is returned when saving over a template without overwrite
+	ErrTemplateExists = errors.New("template already exists")
+)
+
+// Template is a reusable experiment spec in the catalog, stored as JSON
+type Template struct {
+	Namespace   string
+	Name        string
+	Description string
+	Tags        []string
+	Spec        []byte
+	// UsageCount is the number of experiments created from the template
+	UsageCount int
+	// ImportedFrom is the signing key ID of the bundle the template came
+	// from; empty for templates published on this instance
+	ImportedFrom string
+	CreatedBy    string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TemplateFilter narrows ListTemplates; zero values match everything
+type TemplateFilter struct {
+	Namespace string
+	Tag       string
+}
+
+// TemplateStore keeps the experiment template catalog
+type TemplateStore interface {
+	// SaveTemplate creates a template, or replaces it when overwrite is set.
+	// Replacing keeps the usage count.
+	SaveTemplate(ctx context.Context, t *Template, overwrite bool) error
+	// SaveTemplates saves several templates in one transaction: either all
+	// of them are saved or none is
+	SaveTemplates(ctx context.Context, templates []*Template, overwrite bool) error
+	GetTemplate(ctx context.Context, namespace, name string) (*Template, error)
+	// ListTemplates returns the most used templates first
+	ListTemplates(ctx context.Context, filter TemplateFilter) ([]*Template, error)
+	IncrementTemplateUsage(ctx context.Context, namespace, name string) error
+}
+
+// PostgresTemplateStore keeps templates in the experiment_templates table
+type PostgresTemplateStore struct {
+	db *sql.DB
+}
+
+func NewPostgresTemplateStore(db *sql.DB) *PostgresTemplateStore {
+	return &PostgresTemplateStore{db: db}
+}
+
+const templateColumns = `namespace, name, description, tags, spec, usage_count, imported_from, created_by, created_at, updated_at`
+
+func (s *PostgresTemplateStore) SaveTemplate(ctx context.Context, t *Template, overwrite bool) error {
+	return saveTemplate(ctx, s.db, t, overwrite)
+}
+
+func (s *PostgresTemplateStore) SaveTemplates(ctx context.Context, templates []*Template, overwrite bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, t := range templates {
+		if err := saveTemplate(ctx, tx, t, overwrite); err != nil {
+			return fmt.Errorf("%s/%s: %w", t.Namespace, t.Name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// rowQuerier is implemented by *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func saveTemplate(ctx context.Context, db rowQuerier, t *Template, overwrite bool) error {
+	tags, err := json.Marshal(t.Tags)
+	if err != nil {
+		return err
+	}
+	if t.Tags == nil {
+		tags = []byte("[]")
+	}
+
+	conflict := "DO NOTHING"
+	if overwrite {
+		conflict = `DO UPDATE
+		SET description = EXCLUDED.description,
+		    tags = EXCLUDED.tags,
+		    spec = EXCLUDED.spec,
+		    imported_from = EXCLUDED.imported_from,
+		    created_by = EXCLUDED.created_by,
+		    updated_at = NOW()`
+	}
+
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO experiment_templates (namespace, name, description, tags, spec, imported_from, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (namespace, name) `+conflict+`
+		RETURNING usage_count, created_at, updated_at`,
+		t.Namespace, t.Name, t.Description, tags, t.Spec, t.ImportedFrom, t.CreatedBy,
+	).Scan(&t.UsageCount, &t.CreatedAt, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrTemplateExists
+	}
+	return err
+}
+
+func (s *PostgresTemplateStore) GetTemplate(ctx context.Context, namespace, name string) (*Template, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+templateColumns+`
+		FROM experiment_templates
+		WHERE namespace = $1 AND name = $2`, namespace, name)
+	return scanTemplate(row)
+}
+
+func (s *PostgresTemplateStore) ListTemplates(ctx context.Context, filter TemplateFilter) ([]*Template, error) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Namespace != "" {
+		add("namespace = $%d", filter.Namespace)
+	}
+	if filter.Tag != "" {
+		tag, err := json.Marshal([]string{filter.Tag})
+		if err != nil {
+			return nil, err
+		}
+		add("tags @> $%d", tag)
+	}
+
+	clause := ""
+	if len(where) > 0 {
+		clause = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+templateColumns+`
+		FROM experiment_templates
+		`+clause+`
+		ORDER BY usage_count DESC, namespace, name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+func (s *PostgresTemplateStore) IncrementTemplateUsage(ctx context.Context, namespace, name string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE experiment_templates SET usage_count = usage_count + 1
+		WHERE namespace = $1 AND name = $2`, namespace, name)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+func scanTemplate(row interface{ Scan(...interface{}) error }) (*Template, error) {
+	t := &Template{}
+	var tags []byte
+	err := row.Scan(&t.Namespace, &t.Name, &t.Description, &tags, &t.Spec, &t.UsageCount,
+		&t.ImportedFrom, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &t.Tags); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
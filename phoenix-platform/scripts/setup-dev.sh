@@ -99,10 +99,28 @@ GIT_TOKEN=
 NEW_RELIC_API_KEY=
 NEW_RELIC_ACCOUNT_ID=
 NEW_RELIC_OTLP_ENDPOINT=https://otlp.nr-data.net
+NEW_RELIC_INSERT_KEY=
+
+# Experiment result exporters (comma separated: newrelic,datadog)
+RESULT_EXPORTERS=
+DATADOG_API_KEY=
+
+# Event bus backend (postgres, memory, none)
+EVENT_BUS=postgres
+
+# Notifications for anomalies and experiment verdicts
+# Routes: critical=slack,teams;warning=slack;info=webhook (default: all channels)
+NOTIFY_SLACK_WEBHOOK_URL=
+NOTIFY_TEAMS_WEBHOOK_URL=
+NOTIFY_WEBHOOK_URL=
+NOTIFY_ROUTES=
+NOTIFY_RATE_PER_MINUTE=10
 
 # API Configuration
 GRPC_PORT=5050
 HTTP_PORT=8080
+# Enables gRPC reflection (grpcurl, evans); never set in production
+DEV_MODE=true
 
 # Dashboard
 VITE_API_URL=http://localhost:8080/api/v1